@@ -0,0 +1,348 @@
+package main
+
+// Lunar and solar ephemeris based on Jean Meeus, "Astronomical Algorithms"
+// (2nd ed.). Positions are geocentric and accurate to well under an
+// arcminute for the Sun and a few arcseconds for the Moon, which is far more
+// than phase names and illumination need.
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	j2000          = 2451545.0 // Julian Day of 2000-01-01 12:00 TT
+	unixEpochJD    = 2440587.5 // Julian Day of 1970-01-01 00:00 UTC
+	daysPerCentury = 36525.0
+	auKm           = 149597870.7
+	synodicMonth   = 29.530588861
+)
+
+func sind(d float64) float64 { return math.Sin(d * math.Pi / 180) }
+func cosd(d float64) float64 { return math.Cos(d * math.Pi / 180) }
+
+// normDeg reduces an angle to [0, 360).
+func normDeg(d float64) float64 {
+	d = math.Mod(d, 360)
+	if d < 0 {
+		d += 360
+	}
+	return d
+}
+
+// julianDay returns the Julian Day (UT) of t.
+func julianDay(t time.Time) float64 {
+	return unixEpochJD + float64(t.UnixNano())/float64(24*time.Hour)
+}
+
+// timeFromJulianDay converts a Julian Day (UT) to a UTC time.
+func timeFromJulianDay(jd float64) time.Time {
+	ns := (jd - unixEpochJD) * float64(24*time.Hour)
+	return time.Unix(0, int64(math.Round(ns))).UTC()
+}
+
+// deltaT returns TT − UT in seconds for a decimal year, using the
+// polynomial fits by Espenak and Meeus. Outside 1900–2150 it falls back to
+// the long-term parabola, which is good enough for phase names.
+func deltaT(year float64) float64 {
+	switch {
+	case year < 1900:
+		u := (year - 1820) / 100
+		return -20 + 32*u*u
+	case year < 1920:
+		t := year - 1900
+		return -2.79 + 1.494119*t - 0.0598939*t*t + 0.0061966*t*t*t - 0.000197*t*t*t*t
+	case year < 1941:
+		t := year - 1920
+		return 21.20 + 0.84493*t - 0.076100*t*t + 0.0020936*t*t*t
+	case year < 1961:
+		t := year - 1950
+		return 29.07 + 0.407*t - t*t/233 + t*t*t/2547
+	case year < 1986:
+		t := year - 1975
+		return 45.45 + 1.067*t - t*t/260 - t*t*t/718
+	case year < 2005:
+		t := year - 2000
+		return 63.86 + 0.3345*t - 0.060374*t*t + 0.0017275*t*t*t + 0.000651814*t*t*t*t + 0.00002373599*t*t*t*t*t
+	case year < 2050:
+		t := year - 2000
+		return 62.92 + 0.32217*t + 0.005589*t*t
+	case year < 2150:
+		u := (year - 1820) / 100
+		return -20 + 32*u*u - 0.5628*(2150-year)
+	default:
+		u := (year - 1820) / 100
+		return -20 + 32*u*u
+	}
+}
+
+// decimalYear approximates the decimal year of a Julian Day.
+func decimalYear(jd float64) float64 {
+	return 2000 + (jd-j2000)/365.25
+}
+
+// julianEphemerisDay converts a UT Julian Day to Terrestrial Time.
+func julianEphemerisDay(jd float64) float64 {
+	return jd + deltaT(decimalYear(jd))/86400
+}
+
+// centuries returns Julian centuries of TT since J2000.
+func centuries(jde float64) float64 {
+	return (jde - j2000) / daysPerCentury
+}
+
+// eclipticPosition is a geocentric ecliptic position of date.
+type eclipticPosition struct {
+	Longitude float64 // degrees, apparent
+	Latitude  float64 // degrees
+	Distance  float64 // km
+}
+
+// nutation returns the nutation in longitude and the true obliquity of the
+// ecliptic, both in degrees, using the low-accuracy terms of Meeus ch. 22.
+func nutation(T float64) (dPsi, epsilon float64) {
+	omega := 125.04452 - 1934.136261*T
+	L := 280.4665 + 36000.7698*T
+	Lp := 218.3165 + 481267.8813*T
+	dPsi = (-17.20*sind(omega) - 1.32*sind(2*L) - 0.23*sind(2*Lp) + 0.21*sind(2*omega)) / 3600
+	dEps := (9.20*cosd(omega) + 0.57*cosd(2*L) + 0.10*cosd(2*Lp) - 0.09*cosd(2*omega)) / 3600
+	eps0 := 23.4392911 - (46.8150*T+0.00059*T*T-0.001813*T*T*T)/3600
+	return dPsi, eps0 + dEps
+}
+
+// sunPosition returns the apparent geocentric position of the Sun at JDE
+// (Meeus ch. 25, low accuracy).
+func sunPosition(jde float64) eclipticPosition {
+	T := centuries(jde)
+	L0 := 280.46646 + 36000.76983*T + 0.0003032*T*T
+	M := 357.52911 + 35999.05029*T - 0.0001537*T*T
+	e := 0.016708634 - 0.000042037*T - 0.0000001267*T*T
+	C := (1.914602-0.004817*T-0.000014*T*T)*sind(M) +
+		(0.019993-0.000101*T)*sind(2*M) +
+		0.000289*sind(3*M)
+	trueLon := L0 + C
+	nu := M + C
+	R := 1.000001018 * (1 - e*e) / (1 + e*cosd(nu))
+
+	omega := 125.04 - 1934.136*T
+	lon := trueLon - 0.00569 - 0.00478*sind(omega)
+	return eclipticPosition{Longitude: normDeg(lon), Distance: R * auKm}
+}
+
+// Periodic terms for the Moon's longitude and distance (Meeus table 47.A).
+// Multiples of D, M, M', F; Σl coefficient (1e-6 degrees); Σr coefficient
+// (1e-3 km).
+var moonLonDistTerms = [...]struct {
+	D, M, Mp, F float64
+	L, R        float64
+}{
+	{0, 0, 1, 0, 6288774, -20905355},
+	{2, 0, -1, 0, 1274027, -3699111},
+	{2, 0, 0, 0, 658314, -2955968},
+	{0, 0, 2, 0, 213618, -569925},
+	{0, 1, 0, 0, -185116, 48888},
+	{0, 0, 0, 2, -114332, -3149},
+	{2, 0, -2, 0, 58793, 246158},
+	{2, -1, -1, 0, 57066, -152138},
+	{2, 0, 1, 0, 53322, -170733},
+	{2, -1, 0, 0, 45758, -204586},
+	{0, 1, -1, 0, -40923, -129620},
+	{1, 0, 0, 0, -34720, 108743},
+	{0, 1, 1, 0, -30383, 104755},
+	{2, 0, 0, -2, 15327, 10321},
+	{0, 0, 1, 2, -12528, 0},
+	{0, 0, 1, -2, 10980, 79661},
+	{4, 0, -1, 0, 10675, -34782},
+	{0, 0, 3, 0, 10034, -23210},
+	{4, 0, -2, 0, 8548, -21636},
+	{2, 1, -1, 0, -7888, 24208},
+	{2, 1, 0, 0, -6766, 30824},
+	{1, 0, -1, 0, -5163, -8379},
+	{1, 1, 0, 0, 4987, -16675},
+	{2, -1, 1, 0, 4036, -12831},
+	{2, 0, 2, 0, 3994, -10445},
+	{4, 0, 0, 0, 3861, -11650},
+	{2, 0, -3, 0, 3665, 14403},
+	{0, 1, -2, 0, -2689, -7003},
+	{2, 0, -1, 2, -2602, 0},
+	{2, -1, -2, 0, 2390, 10056},
+	{1, 0, 1, 0, -2348, 6322},
+	{2, -2, 0, 0, 2236, -9884},
+	{0, 1, 2, 0, -2120, 5751},
+	{0, 2, 0, 0, -2069, 0},
+	{2, -2, -1, 0, 2048, -4950},
+	{2, 0, 1, -2, -1773, 4130},
+	{2, 0, 0, 2, -1595, 0},
+	{4, -1, -1, 0, 1215, -3958},
+	{0, 0, 2, 2, -1110, 0},
+	{3, 0, -1, 0, -892, 3258},
+	{2, 1, 1, 0, -810, 2616},
+	{4, -1, -2, 0, 759, -1897},
+	{0, 2, -1, 0, -713, -2117},
+	{2, 2, -1, 0, -700, 2354},
+	{2, 1, -2, 0, 691, 0},
+	{2, -1, 0, -2, 596, 0},
+	{4, 0, 1, 0, 549, -1423},
+	{0, 0, 4, 0, 537, -1117},
+	{4, -1, 0, 0, 520, -1571},
+	{1, 0, -2, 0, -487, -1739},
+	{2, 1, 0, -2, -399, 0},
+	{0, 0, 2, -2, -381, -4421},
+	{1, 1, 1, 0, 351, 0},
+	{3, 0, -2, 0, -340, 0},
+	{4, 0, -3, 0, 330, 0},
+	{2, -1, 2, 0, 327, 0},
+	{0, 2, 1, 0, -323, 1165},
+	{1, 1, -1, 0, 299, 0},
+	{2, 0, 3, 0, 294, 0},
+	{2, 0, -1, -2, 0, 8752},
+}
+
+// Periodic terms for the Moon's latitude (Meeus table 47.B). Multiples of
+// D, M, M', F; Σb coefficient (1e-6 degrees).
+var moonLatTerms = [...]struct {
+	D, M, Mp, F float64
+	B           float64
+}{
+	{0, 0, 0, 1, 5128122},
+	{0, 0, 1, 1, 280602},
+	{0, 0, 1, -1, 277693},
+	{2, 0, 0, -1, 173237},
+	{2, 0, -1, 1, 55413},
+	{2, 0, -1, -1, 46271},
+	{2, 0, 0, 1, 32573},
+	{0, 0, 2, 1, 17198},
+	{2, 0, 1, -1, 9266},
+	{0, 0, 2, -1, 8822},
+	{2, -1, 0, -1, 8216},
+	{2, 0, -2, -1, 4324},
+	{2, 0, 1, 1, 4200},
+	{2, 1, 0, -1, -3359},
+	{2, -1, -1, 1, 2463},
+	{2, -1, 0, 1, 2211},
+	{2, -1, -1, -1, 2065},
+	{0, 1, -1, -1, -1870},
+	{4, 0, -1, -1, 1828},
+	{0, 1, 0, 1, -1794},
+	{0, 0, 0, 3, -1749},
+	{0, 1, -1, 1, -1565},
+	{1, 0, 0, 1, -1491},
+	{0, 1, 1, 1, -1475},
+	{0, 1, 1, -1, -1410},
+	{0, 1, 0, -1, -1344},
+	{1, 0, 0, -1, -1335},
+	{0, 0, 3, 1, 1107},
+	{4, 0, 0, -1, 1021},
+	{4, 0, -1, 1, 833},
+	{0, 0, 1, -3, 777},
+	{4, 0, -2, 1, 671},
+	{2, 0, 0, -3, 607},
+	{2, 0, 2, -1, 596},
+	{2, -1, 1, -1, 491},
+	{2, 0, -2, 1, -451},
+	{0, 0, 3, -1, 439},
+	{2, 0, 2, 1, 422},
+	{2, 0, -3, -1, 421},
+	{2, 1, -1, 1, -366},
+	{2, 1, 0, 1, -351},
+	{4, 0, 0, 1, 331},
+	{2, -1, 1, 1, 315},
+	{2, -2, 0, -1, 302},
+	{0, 0, 1, 3, -283},
+	{2, 1, 1, -1, -229},
+	{1, 1, 0, -1, 223},
+	{1, 1, 0, 1, 223},
+	{0, 1, -2, -1, -220},
+	{2, 1, -1, -1, -220},
+	{1, 0, 1, 1, -185},
+	{2, -1, -2, -1, 181},
+	{0, 1, 2, 1, -177},
+	{4, 0, -2, -1, 176},
+	{4, -1, -1, -1, 166},
+	{1, 0, 1, -1, -164},
+	{4, 0, 1, -1, 132},
+	{1, 0, -1, -1, -119},
+	{4, -1, 0, -1, 115},
+	{2, -2, 0, 1, 107},
+}
+
+// moonPosition returns the apparent geocentric position of the Moon at
+// JDE (Meeus ch. 47).
+func moonPosition(jde float64) eclipticPosition {
+	T := centuries(jde)
+	T2, T3, T4 := T*T, T*T*T, T*T*T*T
+
+	Lp := 218.3164477 + 481267.88123421*T - 0.0015786*T2 + T3/538841 - T4/65194000
+	D := 297.8501921 + 445267.1114034*T - 0.0018819*T2 + T3/545868 - T4/113065000
+	M := 357.5291092 + 35999.0502909*T - 0.0001536*T2 + T3/24490000
+	Mp := 134.9633964 + 477198.8675055*T + 0.0087414*T2 + T3/69699 - T4/14712000
+	F := 93.2720950 + 483202.0175233*T - 0.0036539*T2 - T3/3526000 + T4/863310000
+	A1 := 119.75 + 131.849*T
+	A2 := 53.09 + 479264.290*T
+	A3 := 313.45 + 481266.484*T
+	E := 1 - 0.002516*T - 0.0000074*T2
+
+	// Terms involving the Sun's mean anomaly M are scaled by E for each
+	// power of M, to account for the decreasing eccentricity of Earth's orbit.
+	eFactor := func(m float64) float64 {
+		switch math.Abs(m) {
+		case 1:
+			return E
+		case 2:
+			return E * E
+		}
+		return 1
+	}
+
+	var sl, sr, sb float64
+	for _, t := range moonLonDistTerms {
+		arg := t.D*D + t.M*M + t.Mp*Mp + t.F*F
+		e := eFactor(t.M)
+		sl += t.L * e * sind(arg)
+		sr += t.R * e * cosd(arg)
+	}
+	for _, t := range moonLatTerms {
+		arg := t.D*D + t.M*M + t.Mp*Mp + t.F*F
+		sb += t.B * eFactor(t.M) * sind(arg)
+	}
+
+	sl += 3958*sind(A1) + 1962*sind(Lp-F) + 318*sind(A2)
+	sb += -2235*sind(Lp) + 382*sind(A3) + 175*sind(A1-F) + 175*sind(A1+F) +
+		127*sind(Lp-Mp) - 115*sind(Lp+Mp)
+
+	dPsi, _ := nutation(T)
+	return eclipticPosition{
+		Longitude: normDeg(Lp + sl/1e6 + dPsi),
+		Latitude:  sb / 1e6,
+		Distance:  385000.56 + sr/1000,
+	}
+}
+
+// lunarState describes the Moon's phase geometry at an instant.
+type lunarState struct {
+	Elongation   float64 // Moon − Sun ecliptic longitude, degrees in [0, 360)
+	PhaseAngle   float64 // Sun–Moon–Earth angle, degrees
+	Illumination float64 // illuminated fraction of the disk, 0..1
+	Distance     float64 // Earth–Moon distance, km
+	Waxing       bool
+}
+
+// moonState computes the Moon's phase geometry at t (Meeus ch. 48).
+func moonState(t time.Time) lunarState {
+	jde := julianEphemerisDay(julianDay(t))
+	sun := sunPosition(jde)
+	moon := moonPosition(jde)
+
+	elongation := normDeg(moon.Longitude - sun.Longitude)
+	// Geocentric elongation ψ accounts for the Moon's ecliptic latitude
+	psi := math.Acos(cosd(moon.Latitude) * cosd(moon.Longitude-sun.Longitude))
+	phaseAngle := math.Atan2(sun.Distance*math.Sin(psi), moon.Distance-sun.Distance*math.Cos(psi))
+
+	return lunarState{
+		Elongation:   elongation,
+		PhaseAngle:   phaseAngle * 180 / math.Pi,
+		Illumination: (1 + math.Cos(phaseAngle)) / 2,
+		Distance:     moon.Distance,
+		Waxing:       elongation < 180,
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCalendarMonths bounds a ranged get_moon_calendar request.
+const maxCalendarMonths = 24
+
+// calendarRange returns the first and last month (as the first day of
+// each, UTC) covered by a calendar request, and whether the request is
+// ranged: a whole year or an explicit end month. Ranged requests also list
+// per-day entries.
+func calendarRange(input GetMoonCalendarInput) (time.Time, time.Time, bool, error) {
+	if err := validateYear(input.Year); err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	if input.Month == 0 {
+		if input.EndMonth != 0 || input.EndYear != 0 {
+			log.Printf("[ERROR] end_month/end_year given without month")
+			return time.Time{}, time.Time{}, false, fmt.Errorf("end_month and end_year require month")
+		}
+		start := time.Date(input.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 11, 0), true, nil
+	}
+	if input.Month < 1 || input.Month > 12 {
+		log.Printf("[ERROR] Invalid month: %d (must be 1-12)", input.Month)
+		return time.Time{}, time.Time{}, false, fmt.Errorf("month must be between 1 and 12")
+	}
+	start := time.Date(input.Year, time.Month(input.Month), 1, 0, 0, 0, 0, time.UTC)
+	if input.EndMonth == 0 && input.EndYear == 0 {
+		return start, start, false, nil
+	}
+
+	if input.EndMonth < 1 || input.EndMonth > 12 {
+		log.Printf("[ERROR] Invalid end_month: %d (must be 1-12)", input.EndMonth)
+		return time.Time{}, time.Time{}, false, fmt.Errorf("end_month must be between 1 and 12")
+	}
+	endYear := input.EndYear
+	if endYear == 0 {
+		endYear = input.Year
+	}
+	if err := validateYear(endYear); err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+	end := time.Date(endYear, time.Month(input.EndMonth), 1, 0, 0, 0, 0, time.UTC)
+	if end.Before(start) {
+		log.Printf("[ERROR] End month %s is before start month %s", end.Format("2006-01"), start.Format("2006-01"))
+		return time.Time{}, time.Time{}, false, fmt.Errorf("end_month/end_year must not be before month/year")
+	}
+	if months := (end.Year()-start.Year())*12 + int(end.Month()-start.Month()) + 1; months > maxCalendarMonths {
+		log.Printf("[ERROR] Calendar range spans %d months (max %d)", months, maxCalendarMonths)
+		return time.Time{}, time.Time{}, false, fmt.Errorf("calendar range spans %d months, maximum is %d", months, maxCalendarMonths)
+	}
+	return start, end, true, nil
+}
+
+// calendarDays returns one entry per local day in [start, end), with the
+// phase and illumination at local noon.
+func calendarDays(start, end time.Time) []MoonCalendarDay {
+	var days []MoonCalendarDay
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		noon := time.Date(d.Year(), d.Month(), d.Day(), 12, 0, 0, 0, d.Location())
+		phase, illumination, emoji := calculateMoonPhase(noon)
+		days = append(days, MoonCalendarDay{
+			Date:         d.Format("2006-01-02"),
+			Phase:        phase,
+			Illumination: illumination,
+			Emoji:        emoji,
+		})
+	}
+	return days
+}
+
+// icsResult returns a tool result whose content is the calendar as JSON
+// followed by the phase events as an iCalendar (RFC 5545) document.
+func icsResult(calendar MoonCalendarOutput) (*mcp.CallToolResult, error) {
+	data, err := json.Marshal(calendar)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode calendar: %w", err)
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	var b strings.Builder
+	line := func(s string) { b.WriteString(s + "\r\n") }
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//moon-phase-server//Moon Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:Moon Phases")
+	for _, e := range calendar.Events {
+		t, err := time.Parse(time.RFC3339, e.Time)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse event time %q: %w", e.Time, err)
+		}
+		summary := e.Emoji + " " + e.Phase
+		if e.BlueMoon {
+			summary += " (Blue Moon)"
+		}
+		start := t.UTC().Format("20060102T150405Z")
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:%s-%s@moon-phase-server", strings.ToLower(strings.ReplaceAll(e.Phase, " ", "-")), start))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART:" + start)
+		line("SUMMARY:" + summary)
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+			&mcp.TextContent{Text: b.String()},
+		},
+	}, nil
+}
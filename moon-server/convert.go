@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool input/output types
+
+type ConvertLunarDateInput struct {
+	Date      string `json:"date,omitempty" jsonschema:"Gregorian date in YYYY-MM-DD format to convert to lunar calendars; omit to convert a lunar date given by calendar, year, month and day"`
+	Calendar  string `json:"calendar,omitempty" jsonschema:"chinese, hebrew, islamic_tabular or islamic_observed; required for a lunar date, defaults to all for a Gregorian date"`
+	Year      int    `json:"year,omitempty" jsonschema:"lunar year: Gregorian year the Chinese year begins in, Anno Mundi for Hebrew, AH for Islamic"`
+	Month     int    `json:"month,omitempty" jsonschema:"lunar month: 1-12 for Chinese and Islamic; for Hebrew 1 is Nisan, 7 is Tishrei, 12 is Adar (Adar I in leap years) and 13 is Adar II"`
+	Day       int    `json:"day,omitempty" jsonschema:"day of the lunar month"`
+	LeapMonth bool   `json:"leap_month,omitempty" jsonschema:"the Chinese month is the leap (intercalary) month"`
+}
+
+type LunarDate struct {
+	Calendar  string   `json:"calendar"`
+	Year      int      `json:"year"`
+	Month     int      `json:"month"`
+	MonthName string   `json:"month_name"`
+	Day       int      `json:"day"`
+	LeapMonth bool     `json:"leap_month,omitempty"`
+	YearName  string   `json:"year_name,omitempty"`
+	Formatted string   `json:"formatted"`
+	Holidays  []string `json:"holidays,omitempty"`
+}
+
+type ConvertLunarDateOutput struct {
+	Gregorian    string      `json:"gregorian"`
+	Weekday      string      `json:"weekday"`
+	Phase        string      `json:"phase"`
+	Illumination float64     `json:"illumination"`
+	Emoji        string      `json:"emoji"`
+	Dates        []LunarDate `json:"dates"`
+}
+
+var lunarCalendars = []string{"chinese", "hebrew", "islamic_tabular", "islamic_observed"}
+
+// lunarDateFor returns the fixed day rd in the named calendar.
+func lunarDateFor(calendar string, rd int) LunarDate {
+	switch calendar {
+	case "chinese":
+		cd := chineseFromFixed(rd)
+		month := chineseMonthNames[cd.Month]
+		if cd.Leap {
+			month = "leap " + month
+		}
+		name := chineseYearName(cd.CycleYear)
+		return LunarDate{
+			Calendar:  calendar,
+			Year:      cd.Year,
+			Month:     cd.Month,
+			MonthName: month,
+			Day:       cd.Day,
+			LeapMonth: cd.Leap,
+			YearName:  name,
+			Formatted: fmt.Sprintf("%d %s, %s year", cd.Day, month, name),
+			Holidays:  chineseHolidays(rd, cd),
+		}
+	case "hebrew":
+		y, m, d := hebrewFromFixed(rd)
+		month := hebrewMonthName(m, y)
+		return LunarDate{
+			Calendar:  calendar,
+			Year:      y,
+			Month:     m,
+			MonthName: month,
+			Day:       d,
+			Formatted: fmt.Sprintf("%d %s %d", d, month, y),
+			Holidays:  hebrewHolidays(rd, y, m, d),
+		}
+	default:
+		y, m, d := islamicFromFixed(rd)
+		if calendar == "islamic_observed" {
+			y, m, d = observedIslamicFromFixed(rd)
+		}
+		return LunarDate{
+			Calendar:  calendar,
+			Year:      y,
+			Month:     m,
+			MonthName: islamicMonthNames[m],
+			Day:       d,
+			Formatted: fmt.Sprintf("%d %s %d AH", d, islamicMonthNames[m], y),
+			Holidays:  islamicHolidays(m, d),
+		}
+	}
+}
+
+// supportedFixedRange returns the first and last fixed days of the
+// supported Gregorian years.
+func supportedFixedRange() (int, int) {
+	return fixedFromTime(time.Date(minYear, 1, 1, 0, 0, 0, 0, time.UTC)),
+		fixedFromTime(time.Date(maxYear, 12, 31, 0, 0, 0, 0, time.UTC))
+}
+
+// lunarYearRanges holds, per calendar, the first and last years that
+// overlap the supported Gregorian years.
+var lunarYearRanges = sync.OnceValue(func() map[string][2]int {
+	first, last := supportedFixedRange()
+	ranges := make(map[string][2]int)
+	for _, calendar := range lunarCalendars {
+		ranges[calendar] = [2]int{lunarDateFor(calendar, first).Year, lunarDateFor(calendar, last).Year}
+	}
+	return ranges
+})
+
+// validateLunarYear checks that year of calendar overlaps the supported
+// Gregorian years.
+func validateLunarYear(calendar string, year int) error {
+	r := lunarYearRanges()[calendar]
+	if year < r[0] || year > r[1] {
+		return fmt.Errorf("%s year must be between %d and %d (Gregorian %d to %d)", calendar, r[0], r[1], minYear, maxYear)
+	}
+	return nil
+}
+
+// validateLunarDay checks that a date in a partly supported first or last
+// lunar year still falls within the supported Gregorian years.
+func validateLunarDay(calendar string, y, m, d, rd int) error {
+	first, last := supportedFixedRange()
+	if rd < first || rd > last {
+		return fmt.Errorf("%s date %d-%02d-%02d falls on %s, outside %d-01-01 to %d-12-31", calendar, y, m, d, timeFromFixed(rd).Format("2006-01-02"), minYear, maxYear)
+	}
+	return nil
+}
+
+// fixedFromLunar returns the fixed day of the lunar date in input, checking
+// that the month and day exist and that it falls within the supported years.
+func fixedFromLunar(input ConvertLunarDateInput) (int, error) {
+	y, m, d := input.Year, input.Month, input.Day
+	if input.LeapMonth && input.Calendar != "chinese" {
+		return 0, fmt.Errorf("leap_month only applies to the chinese calendar")
+	}
+	if d < 1 {
+		return 0, fmt.Errorf("day must be at least 1")
+	}
+	if err := validateLunarYear(input.Calendar, y); err != nil {
+		return 0, err
+	}
+
+	var rd int
+	switch input.Calendar {
+	case "chinese":
+		if m < 1 || m > 12 {
+			return 0, fmt.Errorf("month must be between 1 and 12")
+		}
+		var err error
+		if rd, err = fixedFromChinese(y, m, input.LeapMonth, d); err != nil {
+			return 0, err
+		}
+
+	case "hebrew":
+		if m < 1 || m > hebrewLastMonth(y) {
+			return 0, fmt.Errorf("month must be between 1 and %d in Hebrew year %d", hebrewLastMonth(y), y)
+		}
+		if length := hebrewMonthLength(m, y); d > length {
+			return 0, fmt.Errorf("%s %d has only %d days", hebrewMonthName(m, y), y, length)
+		}
+		rd = fixedFromHebrew(y, m, d)
+
+	default:
+		if m < 1 || m > 12 {
+			return 0, fmt.Errorf("month must be between 1 and 12")
+		}
+		start, length := fixedFromIslamic(y, m, 1), islamicMonthLength(m, y)
+		if input.Calendar == "islamic_observed" {
+			start, length = observedIslamicMonth(y, m)
+		}
+		if d > length {
+			return 0, fmt.Errorf("%s %d has only %d days", islamicMonthNames[m], y, length)
+		}
+		rd = start + d - 1
+	}
+
+	if err := validateLunarDay(input.Calendar, y, m, d, rd); err != nil {
+		return 0, err
+	}
+	return rd, nil
+}
+
+func convertLunarDate(_ context.Context, _ *mcp.CallToolRequest, input ConvertLunarDateInput) (*mcp.CallToolResult, ConvertLunarDateOutput, error) {
+	log.Printf("[DEBUG] convert_lunar_date tool called with input: date=%s, calendar=%s, year=%d, month=%d, day=%d, leap_month=%t",
+		input.Date, input.Calendar, input.Year, input.Month, input.Day, input.LeapMonth)
+
+	if input.Calendar != "" && !slices.Contains(lunarCalendars, input.Calendar) {
+		log.Printf("[ERROR] Unknown calendar: %s", input.Calendar)
+		return nil, ConvertLunarDateOutput{}, fmt.Errorf("unknown calendar %q (must be one of %s)", input.Calendar, strings.Join(lunarCalendars, ", "))
+	}
+
+	var rd int
+	calendars := lunarCalendars
+	if input.Date != "" {
+		if input.Year != 0 || input.Month != 0 || input.Day != 0 || input.LeapMonth {
+			log.Printf("[ERROR] Both a Gregorian date and a lunar date given")
+			return nil, ConvertLunarDateOutput{}, fmt.Errorf("give either date or year/month/day, not both")
+		}
+		t, err := time.Parse("2006-01-02", input.Date)
+		if err != nil {
+			log.Printf("[ERROR] Invalid date format: %s", input.Date)
+			return nil, ConvertLunarDateOutput{}, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
+		}
+		if err := validateYear(t.Year()); err != nil {
+			return nil, ConvertLunarDateOutput{}, err
+		}
+		rd = fixedFromTime(t)
+		if input.Calendar != "" {
+			calendars = []string{input.Calendar}
+		}
+	} else {
+		if input.Calendar == "" {
+			log.Printf("[ERROR] Lunar date given without calendar")
+			return nil, ConvertLunarDateOutput{}, fmt.Errorf("calendar is required when converting from a lunar date")
+		}
+		var err error
+		if rd, err = fixedFromLunar(input); err != nil {
+			log.Printf("[ERROR] Invalid %s date %d-%d-%d: %v", input.Calendar, input.Year, input.Month, input.Day, err)
+			return nil, ConvertLunarDateOutput{}, err
+		}
+		calendars = []string{input.Calendar}
+	}
+
+	day := timeFromFixed(rd)
+	phase, illumination, emoji := calculateMoonPhase(day.Add(12 * time.Hour))
+	result := ConvertLunarDateOutput{
+		Gregorian:    day.Format("2006-01-02"),
+		Weekday:      day.Weekday().String(),
+		Phase:        phase,
+		Illumination: illumination,
+		Emoji:        emoji,
+		Dates:        []LunarDate{},
+	}
+	for _, calendar := range calendars {
+		result.Dates = append(result.Dates, lunarDateFor(calendar, rd))
+	}
+
+	log.Printf("[DEBUG] Converted %s to %d lunar date(s)", result.Gregorian, len(result.Dates))
+	return nil, result, nil
+}
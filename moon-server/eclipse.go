@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// lunarEclipse describes a lunar eclipse at one full moon.
+type lunarEclipse struct {
+	Type               string    // penumbral, partial or total
+	Greatest           time.Time // instant of greatest eclipse, UTC
+	UmbralMagnitude    float64
+	PenumbralMagnitude float64
+	// Semidurations of the penumbral, partial and total phases in minutes;
+	// zero when the phase does not occur.
+	PenumbralSemiduration float64
+	PartialSemiduration   float64
+	TotalSemiduration     float64
+}
+
+// lunarEclipseAt returns the eclipse at the full moon identified by k (an
+// integer plus 0.5, as for phaseInstant), if there is one (Meeus ch. 54).
+func lunarEclipseAt(k float64) (lunarEclipse, bool) {
+	T := k / 1236.85
+	T2, T3, T4 := T*T, T*T*T, T*T*T*T
+
+	F := 160.7108 + 390.67050284*k - 0.0016118*T2 - 0.00000227*T3 + 0.000000011*T4
+	// Far from a node the Moon misses Earth's shadow entirely
+	if math.Abs(sind(F)) > 0.36 {
+		return lunarEclipse{}, false
+	}
+
+	jde := 2451550.09766 + synodicMonth*k + 0.00015437*T2 - 0.000000150*T3 + 0.00000000073*T4
+	E := 1 - 0.002516*T - 0.0000074*T2
+	M := 2.5534 + 29.10535670*k - 0.0000014*T2 - 0.00000011*T3
+	Mp := 201.5643 + 385.81693528*k + 0.0107582*T2 + 0.00001238*T3 - 0.000000058*T4
+	Om := 124.7746 - 1.56375588*k + 0.0020672*T2 + 0.00000215*T3
+	F1 := F - 0.02665*sind(Om)
+	A1 := 299.77 + 0.107408*k - 0.009173*T2
+
+	jde += -0.4065*sind(Mp) + 0.1727*E*sind(M) + 0.0161*sind(2*Mp) -
+		0.0097*sind(2*F1) + 0.0073*E*sind(Mp-M) - 0.0050*E*sind(Mp+M) -
+		0.0023*sind(Mp-2*F1) + 0.0021*E*sind(2*M) + 0.0012*sind(Mp+2*F1) +
+		0.0006*E*sind(2*Mp+M) - 0.0004*sind(3*Mp) - 0.0003*E*sind(M+2*F1) +
+		0.0003*sind(A1) - 0.0002*E*sind(M-2*F1) - 0.0002*E*sind(2*Mp-M) -
+		0.0002*sind(Om)
+
+	P := 0.2070*E*sind(M) + 0.0024*E*sind(2*M) - 0.0392*sind(Mp) +
+		0.0116*sind(2*Mp) - 0.0073*E*sind(Mp+M) + 0.0067*E*sind(Mp-M) +
+		0.0118*sind(2*F1)
+	Q := 5.2207 - 0.0048*E*cosd(M) + 0.0020*E*cosd(2*M) - 0.3299*cosd(Mp) -
+		0.0060*E*cosd(Mp+M) + 0.0041*E*cosd(Mp-M)
+	W := math.Abs(cosd(F1))
+	// gamma is the least distance from the Moon's centre to the shadow axis,
+	// in Earth radii
+	gamma := math.Abs((P*cosd(F1) + Q*sind(F1)) * (1 - 0.0048*W))
+	u := 0.0059 + 0.0046*E*cosd(M) - 0.0182*cosd(Mp) + 0.0004*cosd(2*Mp) - 0.0005*cosd(M+Mp)
+
+	penumbral := (1.5573 + u - gamma) / 0.5450
+	if penumbral <= 0 {
+		return lunarEclipse{}, false
+	}
+	umbral := (1.0128 - u - gamma) / 0.5450
+
+	n := 0.5458 + 0.0400*cosd(Mp)
+	semiduration := func(radius float64) float64 {
+		if radius <= gamma {
+			return 0
+		}
+		return 60 / n * math.Sqrt(radius*radius-gamma*gamma)
+	}
+
+	eclipse := lunarEclipse{
+		Type:                  "penumbral",
+		Greatest:              timeFromJulianDay(jde - deltaT(decimalYear(jde))/86400),
+		UmbralMagnitude:       umbral,
+		PenumbralMagnitude:    penumbral,
+		PenumbralSemiduration: semiduration(1.5573 + u),
+		PartialSemiduration:   semiduration(1.0128 - u),
+		TotalSemiduration:     semiduration(0.4678 - u),
+	}
+	switch {
+	case umbral >= 1:
+		eclipse.Type = "total"
+	case umbral > 0:
+		eclipse.Type = "partial"
+	}
+	return eclipse, true
+}
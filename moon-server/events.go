@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/timezone"
+)
+
+// Tool input/output types
+
+type FindLunarEventsInput struct {
+	StartDate string   `json:"start_date,omitempty" jsonschema:"first day to search in YYYY-MM-DD format, defaults to today"`
+	EndDate   string   `json:"end_date,omitempty" jsonschema:"last day to search in YYYY-MM-DD format, defaults to one year after start_date"`
+	Types     []string `json:"types,omitempty" jsonschema:"event types to include: lunar_eclipse, supermoon, micromoon, blue_moon (default all)"`
+	Timezone  string   `json:"timezone,omitempty" jsonschema:"IANA timezone name for the dates, local times and calendar months used for blue moons; defaults to UTC"`
+}
+
+type LunarEvent struct {
+	Type            string   `json:"type"`
+	Time            string   `json:"time"`
+	LocalTime       string   `json:"local_time,omitempty"`
+	Date            string   `json:"date"`
+	Description     string   `json:"description"`
+	EclipseType     string   `json:"eclipse_type,omitempty"`
+	Magnitude       *float64 `json:"magnitude,omitempty"`
+	DurationMinutes int      `json:"duration_minutes,omitempty"`
+	DistanceKm      float64  `json:"distance_km,omitempty"`
+}
+
+type LunarEventsOutput struct {
+	StartDate string       `json:"start_date"`
+	EndDate   string       `json:"end_date"`
+	Timezone  string       `json:"timezone"`
+	Events    []LunarEvent `json:"events"`
+}
+
+// Full moon distance thresholds for supermoons and micromoons, following
+// Espenak's definition.
+const (
+	supermoonDistanceKm = 360000
+	micromoonDistanceKm = 405000
+)
+
+// maxEventSpanYears bounds how far a single find_lunar_events call searches.
+const maxEventSpanYears = 20
+
+var lunarEventTypes = []string{"lunar_eclipse", "supermoon", "micromoon", "blue_moon"}
+
+// lunarEventsBetween lists the requested event types for full moons in
+// [start, end). Months for blue moons follow start's location.
+func lunarEventsBetween(start, end time.Time, types []string) []LunarEvent {
+	loc := start.Location()
+	events := []LunarEvent{}
+	add := func(e LunarEvent, at time.Time) {
+		e.Time = formatInstant(at)
+		e.Date = at.In(loc).Format("2006-01-02")
+		if loc != time.UTC {
+			e.LocalTime = formatLocal(at, loc)
+		}
+		events = append(events, e)
+	}
+
+	for k := lunationNumber(start) - 1 + 0.5; ; k++ {
+		full := phaseInstant(k)
+		if !full.Before(end) {
+			break
+		}
+		if full.Before(start) {
+			continue
+		}
+
+		if slices.Contains(types, "lunar_eclipse") {
+			if eclipse, ok := lunarEclipseAt(k); ok {
+				e := LunarEvent{
+					Type:        "lunar_eclipse",
+					EclipseType: eclipse.Type,
+					Description: fmt.Sprintf("%s lunar eclipse", strings.ToUpper(eclipse.Type[:1])+eclipse.Type[1:]),
+				}
+				// Umbral magnitude for partial and total eclipses, penumbral
+				// magnitude for penumbral ones; durations cover that phase
+				magnitude, semiduration := eclipse.UmbralMagnitude, eclipse.PartialSemiduration
+				switch eclipse.Type {
+				case "penumbral":
+					magnitude, semiduration = eclipse.PenumbralMagnitude, eclipse.PenumbralSemiduration
+				case "total":
+					semiduration = eclipse.TotalSemiduration
+				}
+				magnitude = math.Round(magnitude*1000) / 1000
+				e.Magnitude = &magnitude
+				e.DurationMinutes = int(math.Round(2 * semiduration))
+				add(e, eclipse.Greatest)
+			}
+		}
+
+		distance := moonPosition(julianEphemerisDay(julianDay(full))).Distance
+		switch {
+		case distance < supermoonDistanceKm && slices.Contains(types, "supermoon"):
+			add(LunarEvent{
+				Type:        "supermoon",
+				Description: "Full moon near perigee",
+				DistanceKm:  math.Round(distance),
+			}, full)
+		case distance > micromoonDistanceKm && slices.Contains(types, "micromoon"):
+			add(LunarEvent{
+				Type:        "micromoon",
+				Description: "Full moon near apogee",
+				DistanceKm:  math.Round(distance),
+			}, full)
+		}
+
+		// A blue moon is the second full moon in a calendar month
+		if slices.Contains(types, "blue_moon") {
+			prev := phaseInstant(k - 1).In(loc)
+			if local := full.In(loc); prev.Year() == local.Year() && prev.Month() == local.Month() {
+				add(LunarEvent{
+					Type:        "blue_moon",
+					Description: fmt.Sprintf("Second full moon in %s %d", local.Month(), local.Year()),
+				}, full)
+			}
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b LunarEvent) int {
+		return strings.Compare(a.Time, b.Time)
+	})
+	return events
+}
+
+// Tool handler
+
+func findLunarEvents(_ context.Context, _ *mcp.CallToolRequest, input FindLunarEventsInput) (*mcp.CallToolResult, LunarEventsOutput, error) {
+	log.Printf("[DEBUG] find_lunar_events tool called with input: start_date=%s, end_date=%s, types=%v, timezone=%s",
+		input.StartDate, input.EndDate, input.Types, input.Timezone)
+
+	loc, err := timezone.Load(input.Timezone)
+	if err != nil {
+		return nil, LunarEventsOutput{}, err
+	}
+
+	start, err := parseLocalInstant(input.StartDate, "", loc)
+	if err != nil {
+		return nil, LunarEventsOutput{}, fmt.Errorf("start_date: %w", err)
+	}
+	start, _ = localDay(start)
+	lastDay := start.AddDate(1, 0, -1)
+	if input.EndDate != "" {
+		if lastDay, err = parseLocalInstant(input.EndDate, "", loc); err != nil {
+			return nil, LunarEventsOutput{}, fmt.Errorf("end_date: %w", err)
+		}
+	}
+	if lastDay.Before(start) {
+		log.Printf("[ERROR] end_date %s is before start_date %s", lastDay.Format("2006-01-02"), start.Format("2006-01-02"))
+		return nil, LunarEventsOutput{}, fmt.Errorf("end_date must not be before start_date")
+	}
+	for _, d := range []time.Time{start, lastDay} {
+		if err := validateYear(d.Year()); err != nil {
+			return nil, LunarEventsOutput{}, err
+		}
+	}
+	if lastDay.After(start.AddDate(maxEventSpanYears, 0, 0)) {
+		log.Printf("[ERROR] Date range exceeds %d years", maxEventSpanYears)
+		return nil, LunarEventsOutput{}, fmt.Errorf("date range must not exceed %d years", maxEventSpanYears)
+	}
+
+	types := input.Types
+	if len(types) == 0 {
+		types = lunarEventTypes
+	}
+	for _, t := range types {
+		if !slices.Contains(lunarEventTypes, t) {
+			log.Printf("[ERROR] Unknown event type: %s", t)
+			return nil, LunarEventsOutput{}, fmt.Errorf("unknown event type %q (must be one of %s)", t, strings.Join(lunarEventTypes, ", "))
+		}
+	}
+
+	events := lunarEventsBetween(start, lastDay.AddDate(0, 0, 1), types)
+	log.Printf("[DEBUG] Found %d lunar events between %s and %s", len(events), start.Format("2006-01-02"), lastDay.Format("2006-01-02"))
+
+	return nil, LunarEventsOutput{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   lastDay.Format("2006-01-02"),
+		Timezone:  loc.String(),
+		Events:    events,
+	}, nil
+}
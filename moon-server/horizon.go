@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Horizon coordinates, rise/set and transit search for the Sun and Moon.
+
+const earthRadiusKm = 6378.14
+
+// equatorialPosition is a geocentric position in right ascension and
+// declination of date.
+type equatorialPosition struct {
+	RA       float64 // degrees
+	Dec      float64 // degrees
+	Distance float64 // km
+}
+
+// toEquatorial converts an ecliptic position to equatorial coordinates
+// using the true obliquity at JDE (Meeus ch. 13).
+func toEquatorial(p eclipticPosition, jde float64) equatorialPosition {
+	_, eps := nutation(centuries(jde))
+	lon, lat := p.Longitude, p.Latitude
+	ra := math.Atan2(sind(lon)*cosd(eps)-math.Tan(lat*math.Pi/180)*sind(eps), cosd(lon))
+	dec := math.Asin(sind(lat)*cosd(eps) + cosd(lat)*sind(eps)*sind(lon))
+	return equatorialPosition{
+		RA:       normDeg(ra * 180 / math.Pi),
+		Dec:      dec * 180 / math.Pi,
+		Distance: p.Distance,
+	}
+}
+
+// siderealTime returns the apparent Greenwich sidereal time at the UT
+// Julian Day jd, in degrees (Meeus ch. 12).
+func siderealTime(jd float64) float64 {
+	T := (jd - j2000) / daysPerCentury
+	theta := 280.46061837 + 360.98564736629*(jd-j2000) + 0.000387933*T*T - T*T*T/38710000
+	dPsi, eps := nutation(centuries(julianEphemerisDay(jd)))
+	return normDeg(theta + dPsi*cosd(eps))
+}
+
+// sunEquatorial and moonEquatorial return the geocentric equatorial
+// position of the body at t.
+func sunEquatorial(t time.Time) equatorialPosition {
+	jde := julianEphemerisDay(julianDay(t))
+	return toEquatorial(sunPosition(jde), jde)
+}
+
+func moonEquatorial(t time.Time) equatorialPosition {
+	jde := julianEphemerisDay(julianDay(t))
+	return toEquatorial(moonPosition(jde), jde)
+}
+
+// hourAngle returns the local hour angle of pos at t for an observer at
+// longitude lon (east positive), in degrees in (-180, 180].
+func hourAngle(pos equatorialPosition, t time.Time, lon float64) float64 {
+	h := normDeg(siderealTime(julianDay(t)) + lon - pos.RA)
+	if h > 180 {
+		h -= 360
+	}
+	return h
+}
+
+// horizontal returns the geocentric altitude and azimuth (degrees, azimuth
+// measured from north through east) of pos at t for an observer at lat, lon.
+func horizontal(pos equatorialPosition, t time.Time, lat, lon float64) (alt, az float64) {
+	h := hourAngle(pos, t, lon)
+	alt = math.Asin(sind(lat)*sind(pos.Dec)+cosd(lat)*cosd(pos.Dec)*cosd(h)) * 180 / math.Pi
+	az = math.Atan2(sind(h), cosd(h)*sind(lat)-math.Tan(pos.Dec*math.Pi/180)*cosd(lat)) * 180 / math.Pi
+	return alt, normDeg(az + 180)
+}
+
+// horizontalParallax returns the Moon's equatorial horizontal parallax in
+// degrees for a geocentric distance in km.
+func horizontalParallax(distance float64) float64 {
+	return math.Asin(earthRadiusKm/distance) * 180 / math.Pi
+}
+
+// crossing is an instant where a sampled function changes sign.
+type crossing struct {
+	Time   time.Time
+	Rising bool // the function went from negative to positive
+}
+
+// searchStep is the sampling interval for crossings. Both bodies move
+// smoothly enough that a function cannot cross zero twice within it.
+const searchStep = 10 * time.Minute
+
+// findCrossings returns every sign change of f in [start, end), each refined
+// by bisection to about a second.
+func findCrossings(f func(time.Time) float64, start, end time.Time) []crossing {
+	var found []crossing
+	prevT, prevV := start, f(start)
+	for prevT.Before(end) {
+		t := prevT.Add(searchStep)
+		if t.After(end) {
+			t = end
+		}
+		v := f(t)
+		if (prevV < 0) != (v < 0) {
+			lo, hi := prevT, t
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if (f(mid) < 0) == (prevV < 0) {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			if hi.Before(end) {
+				found = append(found, crossing{Time: hi, Rising: prevV < 0})
+			}
+		}
+		prevT, prevV = t, v
+	}
+	return found
+}
+
+// riseSetTransit finds the rises, sets and upper transits of a body during
+// [start, end). altitude must return the body's altitude relative to the
+// horizon used for rising and setting; ha its local hour angle.
+func riseSetTransit(altitude, ha func(time.Time) float64, start, end time.Time) (rises, sets, transits []time.Time) {
+	for _, c := range findCrossings(altitude, start, end) {
+		if c.Rising {
+			rises = append(rises, c.Time)
+		} else {
+			sets = append(sets, c.Time)
+		}
+	}
+	// The hour angle increases through zero at upper transit and wraps from
+	// +180 to -180 at lower transit
+	for _, c := range findCrossings(ha, start, end) {
+		if c.Rising {
+			transits = append(transits, c.Time)
+		}
+	}
+	return rises, sets, transits
+}
+
+// localDay returns the start and end of the calendar day containing t in
+// t's location.
+func localDay(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1)
+}
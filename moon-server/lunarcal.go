@@ -0,0 +1,481 @@
+package main
+
+// Lunar and lunisolar calendar arithmetic, after Dershowitz and Reingold,
+// "Calendrical Calculations". Dates are handled as fixed day numbers (R.D.):
+// day 1 is 0001-01-01 in the proleptic Gregorian calendar.
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// rdUnixEpoch is the fixed day number of 1970-01-01.
+const rdUnixEpoch = 719163
+
+// fixedFromTime returns the fixed day number of t's calendar date.
+func fixedFromTime(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix()/86400) + rdUnixEpoch
+}
+
+// timeFromFixed returns 00:00 UTC on the fixed day rd.
+func timeFromFixed(rd int) time.Time {
+	return time.Unix(int64(rd-rdUnixEpoch)*86400, 0).UTC()
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func mod(a, b int) int {
+	return a - b*floorDiv(a, b)
+}
+
+// amod is like mod but returns b instead of 0.
+func amod(a, b int) int {
+	return b + mod(a, -b)
+}
+
+// Hebrew calendar. Months are numbered from Nisan (1); the year starts in
+// Tishrei (7). Leap years add Adar II as month 13.
+
+const hebrewEpoch = -1373427
+
+var hebrewMonthNames = [...]string{"", "Nisan", "Iyyar", "Sivan", "Tammuz", "Av", "Elul", "Tishrei", "Cheshvan", "Kislev", "Tevet", "Shevat", "Adar", "Adar II"}
+
+func hebrewLeapYear(y int) bool {
+	return mod(7*y+1, 19) < 7
+}
+
+func hebrewLastMonth(y int) int {
+	if hebrewLeapYear(y) {
+		return 13
+	}
+	return 12
+}
+
+// hebrewElapsedDays returns the days from the epoch to the molad of
+// Tishrei of year y, postponed by the molad zaken and lo ADU rules.
+func hebrewElapsedDays(y int) int {
+	months := floorDiv(235*y-234, 19)
+	parts := 12084 + 13753*months
+	days := 29*months + floorDiv(parts, 25920)
+	if mod(3*(days+1), 7) < 3 {
+		days++
+	}
+	return days
+}
+
+func hebrewYearLengthCorrection(y int) int {
+	ny0, ny1, ny2 := hebrewElapsedDays(y-1), hebrewElapsedDays(y), hebrewElapsedDays(y+1)
+	switch {
+	case ny2-ny1 == 356:
+		return 2
+	case ny1-ny0 == 382:
+		return 1
+	}
+	return 0
+}
+
+func hebrewNewYear(y int) int {
+	return hebrewEpoch + hebrewElapsedDays(y) + hebrewYearLengthCorrection(y)
+}
+
+func hebrewMonthLength(m, y int) int {
+	days := hebrewNewYear(y+1) - hebrewNewYear(y)
+	switch {
+	case m == 2 || m == 4 || m == 6 || m == 10 || m == 13:
+		return 29
+	case m == 12 && !hebrewLeapYear(y):
+		return 29
+	case m == 8 && days%10 != 5: // Cheshvan is long only in 355/385-day years
+		return 29
+	case m == 9 && days%10 == 3: // Kislev is short in 353/383-day years
+		return 29
+	}
+	return 30
+}
+
+func fixedFromHebrew(y, m, d int) int {
+	rd := hebrewNewYear(y) + d - 1
+	if m < 7 {
+		for mm := 7; mm <= hebrewLastMonth(y); mm++ {
+			rd += hebrewMonthLength(mm, y)
+		}
+		for mm := 1; mm < m; mm++ {
+			rd += hebrewMonthLength(mm, y)
+		}
+	} else {
+		for mm := 7; mm < m; mm++ {
+			rd += hebrewMonthLength(mm, y)
+		}
+	}
+	return rd
+}
+
+func hebrewFromFixed(rd int) (y, m, d int) {
+	// The estimate can trail the true year by up to two
+	y = int(math.Floor(float64(rd-hebrewEpoch) / (35975351.0 / 98496)))
+	for hebrewNewYear(y+1) <= rd {
+		y++
+	}
+	m = 7
+	if rd >= fixedFromHebrew(y, 1, 1) {
+		m = 1
+	}
+	for rd > fixedFromHebrew(y, m, hebrewMonthLength(m, y)) {
+		m++
+	}
+	return y, m, rd - fixedFromHebrew(y, m, 1) + 1
+}
+
+func hebrewMonthName(m, y int) string {
+	if m == 12 && hebrewLeapYear(y) {
+		return "Adar I"
+	}
+	return hebrewMonthNames[m]
+}
+
+// hebrewHolidays returns the holidays falling on rd, which is day d of
+// month m in year y. Holidays start at sunset on the previous civil day.
+func hebrewHolidays(rd, y, m, d int) []string {
+	var names []string
+	switch {
+	case m == 7 && (d == 1 || d == 2):
+		names = append(names, "Rosh Hashanah")
+	case m == 7 && d == 10:
+		names = append(names, "Yom Kippur")
+	case m == 7 && d >= 15 && d <= 21:
+		names = append(names, "Sukkot")
+		if d == 21 {
+			names = append(names, "Hoshana Rabbah")
+		}
+	case m == 7 && d == 22:
+		names = append(names, "Shemini Atzeret")
+	case m == 7 && d == 23:
+		names = append(names, "Simchat Torah")
+	case m == 11 && d == 15:
+		names = append(names, "Tu BiShvat")
+	case m == hebrewLastMonth(y) && d == 14:
+		names = append(names, "Purim")
+	case m == 1 && d >= 15 && d <= 22:
+		names = append(names, "Passover")
+	case m == 2 && d == 18:
+		names = append(names, "Lag BaOmer")
+	case m == 3 && (d == 6 || d == 7):
+		names = append(names, "Shavuot")
+	case m == 5 && d == 9:
+		names = append(names, "Tisha B'Av")
+	}
+	// Hanukkah runs eight days from 25 Kislev into Tevet
+	if day := rd - fixedFromHebrew(y, 9, 25); day >= 0 && day < 8 {
+		names = append(names, fmt.Sprintf("Hanukkah (day %d)", day+1))
+	}
+	return names
+}
+
+// Islamic calendar, arithmetic (tabular) variant with the common 2, 5, 7,
+// 10, 13, 16, 18, 21, 24, 26, 29 leap-year cycle.
+
+const islamicEpoch = 227015
+
+var islamicMonthNames = [...]string{"", "Muharram", "Safar", "Rabi' al-Awwal", "Rabi' al-Thani", "Jumada al-Awwal", "Jumada al-Thani", "Rajab", "Sha'ban", "Ramadan", "Shawwal", "Dhu al-Qa'dah", "Dhu al-Hijjah"}
+
+func fixedFromIslamic(y, m, d int) int {
+	return islamicEpoch - 1 + (y-1)*354 + floorDiv(3+11*y, 30) + 29*(m-1) + floorDiv(m, 2) + d
+}
+
+func islamicFromFixed(rd int) (y, m, d int) {
+	y = floorDiv(30*(rd-islamicEpoch)+10646, 10631)
+	prior := rd - fixedFromIslamic(y, 1, 1)
+	m = floorDiv(11*prior+330, 325)
+	return y, m, rd - fixedFromIslamic(y, m, 1) + 1
+}
+
+func islamicMonthLength(m, y int) int {
+	if m%2 == 1 || (m == 12 && mod(14+11*y, 30) < 11) {
+		return 30
+	}
+	return 29
+}
+
+// Observation-approximated Islamic calendar. A month starts the day after
+// the first evening on which the new crescent would be seen from Mecca,
+// approximated as the Moon being at least crescentMinAge old and
+// crescentMinAltitude high at sunset.
+
+const (
+	meccaLatitude       = 21.4225
+	meccaLongitude      = 39.8262
+	crescentMinAge      = 15 * time.Hour
+	crescentMinAltitude = 5.0
+)
+
+var meccaZone = time.FixedZone("AST", 3*60*60)
+
+// crescentVisible reports whether the crescent would be seen from Mecca on
+// the evening of the civil day rd.
+func crescentVisible(rd int) bool {
+	day := timeFromFixed(rd)
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, meccaZone)
+	_, sunset := sunCrossings(meccaLatitude, meccaLongitude, sunriseAltitude, dayStart, dayStart.AddDate(0, 0, 1))
+	if sunset.IsZero() {
+		return false
+	}
+	if sunset.Sub(previousPhase(sunset, newMoon)) < crescentMinAge {
+		return false
+	}
+	alt, _, _ := moonAltitude(sunset, meccaLatitude, meccaLongitude)
+	return alt >= crescentMinAltitude
+}
+
+// observedMonthStart returns the first day of the month following the
+// conjunction nm.
+func observedMonthStart(nm time.Time) int {
+	rd := fixedFromTime(nm.In(meccaZone))
+	for !crescentVisible(rd) {
+		rd++
+	}
+	return rd + 1
+}
+
+// observedMonthContaining returns the new moon and first day of the
+// observed month containing rd.
+func observedMonthContaining(rd int) (time.Time, int) {
+	nm := previousPhase(timeFromFixed(rd+1), newMoon)
+	start := observedMonthStart(nm)
+	if start > rd {
+		nm = previousPhase(nm, newMoon)
+		start = observedMonthStart(nm)
+	}
+	return nm, start
+}
+
+func observedIslamicFromFixed(rd int) (y, m, d int) {
+	_, start := observedMonthContaining(rd)
+	// Observed and tabular months differ by a day or two, so the middle of
+	// the observed month identifies the month number
+	y, m, _ = islamicFromFixed(start + 14)
+	return y, m, rd - start + 1
+}
+
+// observedIslamicMonth returns the first day and length of observed
+// month m of year y.
+func observedIslamicMonth(y, m int) (int, int) {
+	nm, start := observedMonthContaining(fixedFromIslamic(y, m, 15))
+	next := observedMonthStart(nextPhase(nm, newMoon))
+	return start, next - start
+}
+
+// islamicHolidays returns the holidays on day d of month m.
+func islamicHolidays(m, d int) []string {
+	switch {
+	case m == 1 && d == 1:
+		return []string{"Islamic New Year"}
+	case m == 1 && d == 10:
+		return []string{"Ashura"}
+	case m == 3 && d == 12:
+		return []string{"Mawlid al-Nabi"}
+	case m == 7 && d == 27:
+		return []string{"Isra and Mi'raj"}
+	case m == 9 && d == 1:
+		return []string{"First day of Ramadan"}
+	case m == 9 && d == 27:
+		return []string{"Laylat al-Qadr"}
+	case m == 10 && d == 1:
+		return []string{"Eid al-Fitr"}
+	case m == 12 && d == 9:
+		return []string{"Day of Arafah"}
+	case m == 12 && d == 10:
+		return []string{"Eid al-Adha"}
+	}
+	return nil
+}
+
+// Chinese lunisolar calendar. Months start on the day (China time) of the
+// new moon; the winter solstice always falls in month 11, and in years with
+// 13 months the first month without a major solar term is the leap month.
+
+const (
+	chineseEpoch     = -963099 // 2637 BCE February 15, start of the first cycle
+	meanTropicalYear = 365.242189
+)
+
+var chineseMonthNames = [...]string{"", "Zhengyue", "Eryue", "Sanyue", "Siyue", "Wuyue", "Liuyue", "Qiyue", "Bayue", "Jiuyue", "Shiyue", "Shiyiyue", "Layue"}
+var chineseStems = [...]string{"Jia", "Yi", "Bing", "Ding", "Wu", "Ji", "Geng", "Xin", "Ren", "Gui"}
+var chineseBranches = [...]string{"Zi", "Chou", "Yin", "Mao", "Chen", "Si", "Wu", "Wei", "Shen", "You", "Xu", "Hai"}
+var chineseAnimals = [...]string{"Rat", "Ox", "Tiger", "Rabbit", "Dragon", "Snake", "Horse", "Goat", "Monkey", "Rooster", "Dog", "Pig"}
+
+// chinaZone returns China's standard time on the fixed day rd: UTC+8 since
+// 1929, Beijing mean time before.
+func chinaZone(rd int) *time.Location {
+	if rd < fixedFromTime(time.Date(1929, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		return time.FixedZone("BMT", 7*3600+45*60+40)
+	}
+	return time.FixedZone("CST", 8*3600)
+}
+
+func chinaMidnight(rd int) time.Time {
+	d := timeFromFixed(rd)
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, chinaZone(rd))
+}
+
+func chinaDate(t time.Time) int {
+	rd := fixedFromTime(t.UTC())
+	return fixedFromTime(t.In(chinaZone(rd)))
+}
+
+func solarLongitude(t time.Time) float64 {
+	return sunPosition(julianEphemerisDay(julianDay(t))).Longitude
+}
+
+// estimatePriorSolarLongitude returns an instant close to the last time
+// before t that the Sun's longitude was lambda.
+func estimatePriorSolarLongitude(lambda float64, t time.Time) time.Time {
+	rate := meanTropicalYear / 360 * float64(24*time.Hour)
+	tau := t.Add(-time.Duration(rate * normDeg(solarLongitude(t)-lambda)))
+	delta := normDeg(solarLongitude(tau)-lambda+180) - 180
+	if est := tau.Add(-time.Duration(rate * delta)); est.Before(t) {
+		return est
+	}
+	return t
+}
+
+func chineseWinterSolsticeOnOrBefore(rd int) int {
+	approx := estimatePriorSolarLongitude(270, chinaMidnight(rd+1))
+	day := chinaDate(approx) - 1
+	for solarLongitude(chinaMidnight(day+1)) <= 270 {
+		day++
+	}
+	return day
+}
+
+func chineseNewMoonOnOrAfter(rd int) int {
+	return chinaDate(nextPhase(chinaMidnight(rd).Add(-time.Nanosecond), newMoon))
+}
+
+func chineseNewMoonBefore(rd int) int {
+	return chinaDate(previousPhase(chinaMidnight(rd), newMoon))
+}
+
+func chineseMajorSolarTerm(rd int) int {
+	return amod(2+int(math.Floor(solarLongitude(chinaMidnight(rd))/30)), 12)
+}
+
+func chineseNoMajorSolarTerm(rd int) bool {
+	return chineseMajorSolarTerm(rd) == chineseMajorSolarTerm(chineseNewMoonOnOrAfter(rd+1))
+}
+
+func chinesePriorLeapMonth(mPrime, m int) bool {
+	return m >= mPrime && (chineseNoMajorSolarTerm(m) || chinesePriorLeapMonth(mPrime, chineseNewMoonBefore(m)))
+}
+
+func chineseNewYearInSui(rd int) int {
+	s1 := chineseWinterSolsticeOnOrBefore(rd)
+	s2 := chineseWinterSolsticeOnOrBefore(s1 + 370)
+	m12 := chineseNewMoonOnOrAfter(s1 + 1)
+	m13 := chineseNewMoonOnOrAfter(m12 + 1)
+	nextM11 := chineseNewMoonBefore(s2 + 1)
+	if math.Round(float64(nextM11-m12)/synodicMonth) == 12 && (chineseNoMajorSolarTerm(m12) || chineseNoMajorSolarTerm(m13)) {
+		return chineseNewMoonOnOrAfter(m13 + 1)
+	}
+	return m13
+}
+
+func chineseNewYearOnOrBefore(rd int) int {
+	if ny := chineseNewYearInSui(rd); rd >= ny {
+		return ny
+	}
+	return chineseNewYearInSui(rd - 180)
+}
+
+// chineseDate is a date in the Chinese calendar. Year is the Gregorian
+// year in which the Chinese year begins.
+type chineseDate struct {
+	Year      int
+	CycleYear int // 1-60 in the sexagenary cycle
+	Month     int
+	Leap      bool
+	Day       int
+}
+
+func chineseFromFixed(rd int) chineseDate {
+	s1 := chineseWinterSolsticeOnOrBefore(rd)
+	s2 := chineseWinterSolsticeOnOrBefore(s1 + 370)
+	nextM11 := chineseNewMoonBefore(s2 + 1)
+	m12 := chineseNewMoonOnOrAfter(s1 + 1)
+	leapYear := math.Round(float64(nextM11-m12)/synodicMonth) == 12
+
+	m := chineseNewMoonBefore(rd + 1)
+	month := int(math.Round(float64(m-m12) / synodicMonth))
+	if leapYear && chinesePriorLeapMonth(m12, m) {
+		month--
+	}
+	month = amod(month, 12)
+	leap := leapYear && chineseNoMajorSolarTerm(m) && !chinesePriorLeapMonth(m12, chineseNewMoonBefore(m))
+	elapsed := int(math.Floor(1.5 - float64(month)/12 + float64(rd-chineseEpoch)/meanTropicalYear))
+
+	return chineseDate{
+		Year:      elapsed - 2637,
+		CycleYear: amod(elapsed, 60),
+		Month:     month,
+		Leap:      leap,
+		Day:       rd - m + 1,
+	}
+}
+
+// fixedFromChinese returns the fixed day of a Chinese date, or an error if
+// the month is not a leap month when leap is set or the day is out of range.
+func fixedFromChinese(year, month int, leap bool, day int) (int, error) {
+	midYear := int(math.Floor(chineseEpoch + (float64(year+2637-1)+0.5)*meanTropicalYear))
+	newYear := chineseNewYearOnOrBefore(midYear)
+	p := chineseNewMoonOnOrAfter(newYear + (month-1)*29)
+	if d := chineseFromFixed(p); d.Month != month || d.Leap != leap {
+		p = chineseNewMoonOnOrAfter(p + 1)
+	}
+	if d := chineseFromFixed(p); d.Month != month || d.Leap != leap {
+		return 0, fmt.Errorf("%d has no leap month %d", year, month)
+	}
+	if length := chineseNewMoonOnOrAfter(p+1) - p; day > length {
+		return 0, fmt.Errorf("month %d of %d has only %d days", month, year, length)
+	}
+	return p + day - 1, nil
+}
+
+func chineseYearName(cycleYear int) string {
+	return fmt.Sprintf("%s-%s (%s)", chineseStems[(cycleYear-1)%10], chineseBranches[(cycleYear-1)%12], chineseAnimals[(cycleYear-1)%12])
+}
+
+// chineseHolidays returns the traditional festivals on rd, which is the
+// Chinese date cd.
+func chineseHolidays(rd int, cd chineseDate) []string {
+	var names []string
+	if !cd.Leap {
+		switch {
+		case cd.Month == 1 && cd.Day == 1:
+			names = append(names, "Spring Festival (Chinese New Year)")
+		case cd.Month == 1 && cd.Day == 15:
+			names = append(names, "Lantern Festival")
+		case cd.Month == 5 && cd.Day == 5:
+			names = append(names, "Dragon Boat Festival")
+		case cd.Month == 7 && cd.Day == 7:
+			names = append(names, "Qixi Festival")
+		case cd.Month == 7 && cd.Day == 15:
+			names = append(names, "Ghost Festival")
+		case cd.Month == 8 && cd.Day == 15:
+			names = append(names, "Mid-Autumn Festival")
+		case cd.Month == 9 && cd.Day == 9:
+			names = append(names, "Double Ninth Festival")
+		case cd.Month == 12 && cd.Day == 8:
+			names = append(names, "Laba Festival")
+		}
+	}
+	if chineseNewYearOnOrBefore(rd+1) == rd+1 {
+		names = append(names, "Chinese New Year's Eve")
+	}
+	return names
+}
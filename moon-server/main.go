@@ -1,5 +1,6 @@
 // Moon Phase MCP Server
-// A simple MCP server that provides moon phase information using public APIs.
+// A simple MCP server that provides moon phase information computed from a
+// lunar ephemeris, without any external API.
 // Supports StreamableHTTP transport for gateway testing.
 package main
 
@@ -9,209 +10,254 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/timezone"
 )
 
 // Tool input/output types
 
 type GetMoonPhaseInput struct {
-	Date string `json:"date,omitempty" jsonschema:"date in YYYY-MM-DD format, defaults to today"`
+	Date     string `json:"date,omitempty" jsonschema:"date in YYYY-MM-DD format, defaults to today"`
+	Time     string `json:"time,omitempty" jsonschema:"local time of day in HH:MM format (24-hour); defaults to midnight when date is given, otherwise now"`
+	Timezone string `json:"timezone,omitempty" jsonschema:"IANA timezone name for date and time, e.g. Europe/Berlin; defaults to UTC"`
 }
 
 type MoonPhaseOutput struct {
 	Date          string  `json:"date"`
+	Time          string  `json:"time"`
+	Timezone      string  `json:"timezone"`
 	Phase         string  `json:"phase"`
 	Illumination  float64 `json:"illumination"`
+	Elongation    float64 `json:"elongation"`
 	DaysUntilFull int     `json:"days_until_full"`
+	NextNewMoon   string  `json:"next_new_moon"`
+	NextFullMoon  string  `json:"next_full_moon"`
 	Emoji         string  `json:"emoji"`
 }
 
 type GetMoonCalendarInput struct {
-	Month int `json:"month" jsonschema:"month number (1-12)"`
-	Year  int `json:"year" jsonschema:"year (e.g., 2025)"`
+	Month    int    `json:"month,omitempty" jsonschema:"month number (1-12); omit for the whole year"`
+	Year     int    `json:"year" jsonschema:"year (e.g., 2025)"`
+	EndMonth int    `json:"end_month,omitempty" jsonschema:"last month (1-12) of a multi-month range starting at month"`
+	EndYear  int    `json:"end_year,omitempty" jsonschema:"year of end_month, defaults to year"`
+	Timezone string `json:"timezone,omitempty" jsonschema:"IANA timezone name, e.g. America/New_York; the month and dates follow this zone's local days (default UTC)"`
+	Format   string `json:"format,omitempty" jsonschema:"json (default) or ics to also return the phases as an iCalendar text block"`
+}
+
+type MoonPhaseEvent struct {
+	Phase     string `json:"phase"`
+	Time      string `json:"time"`
+	Date      string `json:"date"`
+	LocalTime string `json:"local_time,omitempty"`
+	LocalDate string `json:"local_date,omitempty"`
+	Emoji     string `json:"emoji"`
+	BlueMoon  bool   `json:"blue_moon,omitempty"`
+}
+
+type MoonCalendarDay struct {
+	Date         string  `json:"date"`
+	Phase        string  `json:"phase"`
+	Illumination float64 `json:"illumination"`
+	Emoji        string  `json:"emoji"`
 }
 
 type MoonCalendarOutput struct {
-	Month    int    `json:"month"`
-	Year     int    `json:"year"`
-	NewMoon  string `json:"new_moon"`
-	FirstQtr string `json:"first_quarter"`
-	FullMoon string `json:"full_moon"`
-	LastQtr  string `json:"last_quarter"`
+	Month    int               `json:"month"`
+	Year     int               `json:"year"`
+	EndMonth int               `json:"end_month,omitempty"`
+	EndYear  int               `json:"end_year,omitempty"`
+	Timezone string            `json:"timezone,omitempty"`
+	Events   []MoonPhaseEvent  `json:"events"`
+	Days     []MoonCalendarDay `json:"days,omitempty"`
+	NewMoon  string            `json:"new_moon"`
+	FirstQtr string            `json:"first_quarter"`
+	FullMoon string            `json:"full_moon"`
+	LastQtr  string            `json:"last_quarter"`
 }
 
-// Moon phase calculation (simplified algorithm)
+// calculateMoonPhase returns the phase name, illuminated percentage of the
+// disk and emoji at t. A principal phase name is used for the whole calendar
+// day (in t's location) on which that phase occurs; other days get the
+// intermediate name matching the Moon's elongation from the Sun.
 func calculateMoonPhase(t time.Time) (string, float64, string) {
-	// Simplified moon phase calculation
-	// Based on the synodic month (29.53 days)
-	const synodicMonth = 29.53058867
-
-	// Known new moon: January 6, 2000
-	knownNewMoon := time.Date(2000, 1, 6, 18, 14, 0, 0, time.UTC)
-	daysSince := t.Sub(knownNewMoon).Hours() / 24
-
-	// Current position in the lunar cycle
-	cyclePosition := daysSince / synodicMonth
-	cyclePosition = cyclePosition - float64(int(cyclePosition)) // Get fractional part
-	if cyclePosition < 0 {
-		cyclePosition += 1
-	}
+	state := moonState(t)
+	illumination := math.Round(state.Illumination*1000) / 10
 
-	// Illumination (approximate)
-	illumination := (1 - (1 + float64(int(cyclePosition*100)%100-50)/50)) / 2
-	if cyclePosition < 0.5 {
-		illumination = cyclePosition * 2
-	} else {
-		illumination = (1 - cyclePosition) * 2
+	if event, ok := phaseOnDay(t); ok {
+		return event.Phase.String(), illumination, phaseEmojis[event.Phase]
 	}
 
-	// Determine phase name and emoji
-	var phase, emoji string
 	switch {
-	case cyclePosition < 0.0625:
-		phase, emoji = "New Moon", "🌑"
-	case cyclePosition < 0.1875:
-		phase, emoji = "Waxing Crescent", "🌒"
-	case cyclePosition < 0.3125:
-		phase, emoji = "First Quarter", "🌓"
-	case cyclePosition < 0.4375:
-		phase, emoji = "Waxing Gibbous", "🌔"
-	case cyclePosition < 0.5625:
-		phase, emoji = "Full Moon", "🌕"
-	case cyclePosition < 0.6875:
-		phase, emoji = "Waning Gibbous", "🌖"
-	case cyclePosition < 0.8125:
-		phase, emoji = "Last Quarter", "🌗"
-	case cyclePosition < 0.9375:
-		phase, emoji = "Waning Crescent", "🌘"
+	case state.Elongation < 90:
+		return "Waxing Crescent", illumination, "🌒"
+	case state.Elongation < 180:
+		return "Waxing Gibbous", illumination, "🌔"
+	case state.Elongation < 270:
+		return "Waning Gibbous", illumination, "🌖"
 	default:
-		phase, emoji = "New Moon", "🌑"
+		return "Waning Crescent", illumination, "🌘"
 	}
-
-	return phase, illumination * 100, emoji
 }
 
+// daysUntilFullMoon returns the whole days from t to the next full moon.
 func daysUntilFullMoon(t time.Time) int {
-	const synodicMonth = 29.53058867
-	knownNewMoon := time.Date(2000, 1, 6, 18, 14, 0, 0, time.UTC)
-	daysSince := t.Sub(knownNewMoon).Hours() / 24
-	cyclePosition := daysSince / synodicMonth
-	cyclePosition = cyclePosition - float64(int(cyclePosition))
-	if cyclePosition < 0 {
-		cyclePosition += 1
-	}
+	return int(nextPhase(t, fullMoon).Sub(t).Hours() / 24)
+}
+
+// formatInstant formats a phase instant to the minute in RFC 3339.
+func formatInstant(t time.Time) string {
+	return t.Round(time.Minute).Format(time.RFC3339)
+}
+
+// Years supported by the ephemeris-based tools. Delta T and the truncated
+// series are only checked within this range.
+const (
+	minYear = 1900
+	maxYear = 2100
+)
 
-	// Full moon is at position 0.5
-	daysToFull := (0.5 - cyclePosition) * synodicMonth
-	if daysToFull < 0 {
-		daysToFull += synodicMonth
+func validateYear(year int) error {
+	if year < minYear || year > maxYear {
+		log.Printf("[ERROR] Invalid year: %d (must be %d-%d)", year, minYear, maxYear)
+		return fmt.Errorf("year must be between %d and %d", minYear, maxYear)
 	}
-	return int(daysToFull)
+	return nil
 }
 
 // Tool handlers
 
 func getMoonPhase(_ context.Context, _ *mcp.CallToolRequest, input GetMoonPhaseInput) (*mcp.CallToolResult, MoonPhaseOutput, error) {
-	log.Printf("[DEBUG] get_moon_phase tool called with input: date=%s", input.Date)
-
-	var t time.Time
-	var err error
+	log.Printf("[DEBUG] get_moon_phase tool called with input: date=%s, time=%s, timezone=%s", input.Date, input.Time, input.Timezone)
 
-	if input.Date == "" {
-		t = time.Now()
-		log.Printf("[DEBUG] No date provided, using current time: %s", t.Format("2006-01-02"))
-	} else {
-		t, err = time.Parse("2006-01-02", input.Date)
-		if err != nil {
-			log.Printf("[ERROR] Invalid date format: %v", err)
-			return nil, MoonPhaseOutput{}, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
-		}
-		log.Printf("[DEBUG] Parsed date: %s", t.Format("2006-01-02"))
+	loc, err := timezone.Load(input.Timezone)
+	if err != nil {
+		return nil, MoonPhaseOutput{}, err
+	}
+	t, err := parseLocalInstant(input.Date, input.Time, loc)
+	if err != nil {
+		return nil, MoonPhaseOutput{}, err
+	}
+	if err := validateYear(t.Year()); err != nil {
+		return nil, MoonPhaseOutput{}, err
 	}
+	log.Printf("[DEBUG] Computing moon phase at %s", t.Format(time.RFC3339))
 
 	phase, illumination, emoji := calculateMoonPhase(t)
 	daysToFull := daysUntilFullMoon(t)
+	elongation := math.Round(moonState(t).Elongation*10) / 10
 
-	log.Printf("[DEBUG] Moon phase calculated: phase=%s, illumination=%.2f%%, days_until_full=%d",
-		phase, illumination, daysToFull)
+	log.Printf("[DEBUG] Moon phase calculated: phase=%s, illumination=%.1f%%, elongation=%.1f, days_until_full=%d",
+		phase, illumination, elongation, daysToFull)
 
 	return nil, MoonPhaseOutput{
 		Date:          t.Format("2006-01-02"),
+		Time:          t.Format(time.RFC3339),
+		Timezone:      loc.String(),
 		Phase:         phase,
 		Illumination:  illumination,
+		Elongation:    elongation,
 		DaysUntilFull: daysToFull,
+		NextNewMoon:   formatInstant(nextPhase(t, newMoon)),
+		NextFullMoon:  formatInstant(nextPhase(t, fullMoon)),
 		Emoji:         emoji,
 	}, nil
 }
 
 func getMoonCalendar(_ context.Context, _ *mcp.CallToolRequest, input GetMoonCalendarInput) (*mcp.CallToolResult, MoonCalendarOutput, error) {
-	log.Printf("[DEBUG] get_moon_calendar tool called with input: month=%d, year=%d", input.Month, input.Year)
+	log.Printf("[DEBUG] get_moon_calendar tool called with input: month=%d, year=%d, end_month=%d, end_year=%d, timezone=%s, format=%s",
+		input.Month, input.Year, input.EndMonth, input.EndYear, input.Timezone, input.Format)
 
-	if input.Month < 1 || input.Month > 12 {
-		log.Printf("[ERROR] Invalid month: %d (must be 1-12)", input.Month)
-		return nil, MoonCalendarOutput{}, fmt.Errorf("month must be between 1 and 12")
+	startMonth, endMonth, ranged, err := calendarRange(input)
+	if err != nil {
+		return nil, MoonCalendarOutput{}, err
 	}
-	if input.Year < 1900 || input.Year > 2100 {
-		log.Printf("[ERROR] Invalid year: %d (must be 1900-2100)", input.Year)
-		return nil, MoonCalendarOutput{}, fmt.Errorf("year must be between 1900 and 2100")
+	if input.Format != "" && input.Format != "json" && input.Format != "ics" {
+		log.Printf("[ERROR] Invalid format: %s", input.Format)
+		return nil, MoonCalendarOutput{}, fmt.Errorf("format must be json or ics")
 	}
 
-	// Find key moon phases in the given month
-	startDate := time.Date(input.Year, time.Month(input.Month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0)
+	loc, err := timezone.Load(input.Timezone)
+	if err != nil {
+		return nil, MoonCalendarOutput{}, err
+	}
+
+	// Find every principal phase instant in the given months, bounded by
+	// local midnights
+	startDate := time.Date(startMonth.Year(), startMonth.Month(), 1, 0, 0, 0, 0, loc)
+	endDate := time.Date(endMonth.Year(), endMonth.Month()+1, 1, 0, 0, 0, 0, loc)
 
 	log.Printf("[DEBUG] Calculating moon calendar from %s to %s",
 		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
 
-	var newMoon, firstQtr, fullMoon, lastQtr string
-
-	for d := startDate; d.Before(endDate); d = d.AddDate(0, 0, 1) {
-		phase, _, _ := calculateMoonPhase(d)
-		prevPhase, _, _ := calculateMoonPhase(d.AddDate(0, 0, -1))
-
-		if phase != prevPhase {
-			dateStr := d.Format("2006-01-02")
-			switch phase {
-			case "New Moon":
-				if newMoon == "" {
-					newMoon = dateStr
-					log.Printf("[DEBUG] Found New Moon on %s", dateStr)
-				}
-			case "First Quarter":
-				if firstQtr == "" {
-					firstQtr = dateStr
-					log.Printf("[DEBUG] Found First Quarter on %s", dateStr)
-				}
-			case "Full Moon":
-				if fullMoon == "" {
-					fullMoon = dateStr
-					log.Printf("[DEBUG] Found Full Moon on %s", dateStr)
-				}
-			case "Last Quarter":
-				if lastQtr == "" {
-					lastQtr = dateStr
-					log.Printf("[DEBUG] Found Last Quarter on %s", dateStr)
-				}
-			}
+	result := MoonCalendarOutput{
+		Month:    int(startMonth.Month()),
+		Year:     startMonth.Year(),
+		Timezone: input.Timezone,
+		Events:   []MoonPhaseEvent{},
+	}
+	if ranged {
+		result.EndMonth, result.EndYear = int(endMonth.Month()), endMonth.Year()
+	}
+	var lastFullMoon time.Time
+	for _, e := range phaseEvents(startDate, endDate) {
+		event := MoonPhaseEvent{
+			Phase: e.Phase.String(),
+			Time:  formatInstant(e.Time),
+			Date:  e.Time.Format("2006-01-02"),
+			Emoji: phaseEmojis[e.Phase],
+		}
+		if input.Timezone != "" {
+			local := e.Time.Round(time.Minute).In(loc)
+			event.LocalTime = local.Format(time.RFC3339)
+			event.LocalDate = local.Format("2006-01-02")
+		}
+		// The second full moon in a calendar month is a blue moon
+		if e.Phase == fullMoon {
+			local := e.Time.In(loc)
+			event.BlueMoon = !lastFullMoon.IsZero() &&
+				lastFullMoon.Year() == local.Year() && lastFullMoon.Month() == local.Month()
+			lastFullMoon = local
+		}
+		log.Printf("[DEBUG] Found %s at %s", event.Phase, event.Time)
+		result.Events = append(result.Events, event)
+
+		// The summary fields keep the local date of the first occurrence of
+		// each phase
+		var first *string
+		switch e.Phase {
+		case newMoon:
+			first = &result.NewMoon
+		case firstQuarter:
+			first = &result.FirstQtr
+		case fullMoon:
+			first = &result.FullMoon
+		case lastQuarter:
+			first = &result.LastQtr
+		}
+		if *first == "" {
+			*first = e.Time.In(loc).Format("2006-01-02")
 		}
 	}
-
-	result := MoonCalendarOutput{
-		Month:    input.Month,
-		Year:     input.Year,
-		NewMoon:  newMoon,
-		FirstQtr: firstQtr,
-		FullMoon: fullMoon,
-		LastQtr:  lastQtr,
+	if ranged {
+		result.Days = calendarDays(startDate, endDate)
 	}
 
-	log.Printf("[DEBUG] Moon calendar result: NewMoon=%s, FirstQtr=%s, FullMoon=%s, LastQtr=%s",
-		newMoon, firstQtr, fullMoon, lastQtr)
+	log.Printf("[DEBUG] Moon calendar result: %d events, %d days, NewMoon=%s, FirstQtr=%s, FullMoon=%s, LastQtr=%s",
+		len(result.Events), len(result.Days), result.NewMoon, result.FirstQtr, result.FullMoon, result.LastQtr)
 
+	if input.Format == "ics" {
+		res, err := icsResult(result)
+		if err != nil {
+			return nil, MoonCalendarOutput{}, err
+		}
+		return res, result, nil
+	}
 	return nil, result, nil
 }
 
@@ -313,7 +359,7 @@ func main() {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "get_moon_phase",
-			Description: "Get the current moon phase for a specific date. Returns phase name, illumination percentage, days until full moon, and emoji.",
+			Description: "Get the moon phase at a local date and time in an IANA timezone (default: now, UTC). Returns phase name, illumination percentage, elongation from the Sun, days until full moon, the next new and full moon instants, and emoji.",
 		},
 		getMoonPhase,
 	)
@@ -321,11 +367,39 @@ func main() {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "get_moon_calendar",
-			Description: "Get the moon phase calendar for a specific month, showing dates of new moon, first quarter, full moon, and last quarter.",
+			Description: "Get the moon phase calendar for a month, a range of months (end_month/end_year), or a whole year (omit month). Lists every new moon, first quarter, full moon, and last quarter with its exact UTC time (and local time when a timezone is given, in which case the month follows local days), flags blue moons, and summarizes the first date of each phase. Ranges and years also list each day's phase, illumination and emoji. Set format to ics to also get an iCalendar text block.",
 		},
 		getMoonCalendar,
 	)
-	log.Printf("[DEBUG] Tools added: get_moon_phase, get_moon_calendar")
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_moon_times",
+			Description: "Get moonrise, moonset and transit times for coordinates on a local date, plus the Moon's altitude, azimuth, distance and illumination at a given time. Computed offline.",
+		},
+		getMoonTimes,
+	)
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_sun_times",
+			Description: "Get sunrise, sunset, solar noon, day length, and civil, nautical and astronomical twilight for coordinates on a local date. Reports polar day and polar night. Computed offline.",
+		},
+		getSunTimes,
+	)
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "find_lunar_events",
+			Description: "Find lunar eclipses (penumbral, partial, total) with magnitude and duration, supermoons, micromoons, and blue moons between two dates (default: the next year). Computed offline for 1900-2100.",
+		},
+		findLunarEvents,
+	)
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "convert_lunar_date",
+			Description: "Convert a Gregorian date to the Chinese, Hebrew and Islamic (tabular and observation-approximated) calendars, or a date in one of those calendars to Gregorian. Returns month names, the Chinese year name, holidays on that day, and the moon phase. Month boundaries come from the phase engine. Supports 1900-2100.",
+		},
+		convertLunarDate,
+	)
+	log.Printf("[DEBUG] Tools added: get_moon_phase, get_moon_calendar, get_moon_times, get_sun_times, find_lunar_events, convert_lunar_date")
 
 	// Create StreamableHTTP handler
 	log.Printf("[DEBUG] Creating StreamableHTTP handler...")
@@ -388,7 +462,7 @@ func main() {
 	log.Printf("Address: %s", addr)
 	log.Printf("Health endpoint: http://localhost%s/health", addr)
 	log.Printf("MCP endpoint: http://localhost%s/mcp", addr)
-	log.Printf("Available tools: get_moon_phase, get_moon_calendar")
+	log.Printf("Available tools: get_moon_phase, get_moon_calendar, get_moon_times, get_sun_times, find_lunar_events, convert_lunar_date")
 	log.Printf("========================================")
 	log.Printf("[DEBUG] Starting HTTP server on %s...", addr)
 
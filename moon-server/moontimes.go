@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/coords"
+	"shared/timezone"
+)
+
+// Tool input/output types
+
+type GetMoonTimesInput struct {
+	Latitude  float64 `json:"latitude" jsonschema:"latitude coordinate (-90 to 90)"`
+	Longitude float64 `json:"longitude" jsonschema:"longitude coordinate (-180 to 180)"`
+	Date      string  `json:"date,omitempty" jsonschema:"local date in YYYY-MM-DD format, defaults to today"`
+	Time      string  `json:"time,omitempty" jsonschema:"local time of day in HH:MM format (24-hour) for the position; defaults to now when date is omitted, otherwise midnight"`
+	Timezone  string  `json:"timezone,omitempty" jsonschema:"IANA timezone name for date, time and results, e.g. Europe/Berlin; defaults to UTC"`
+}
+
+type MoonPosition struct {
+	Time         string  `json:"time"`
+	Altitude     float64 `json:"altitude"`
+	Azimuth      float64 `json:"azimuth"`
+	DistanceKm   float64 `json:"distance_km"`
+	Illumination float64 `json:"illumination"`
+}
+
+type MoonTimesOutput struct {
+	Latitude        float64      `json:"latitude"`
+	Longitude       float64      `json:"longitude"`
+	Date            string       `json:"date"`
+	Timezone        string       `json:"timezone"`
+	Moonrise        string       `json:"moonrise,omitempty"`
+	Moonset         string       `json:"moonset,omitempty"`
+	Transit         string       `json:"transit,omitempty"`
+	TransitAltitude *float64     `json:"transit_altitude,omitempty"`
+	Position        MoonPosition `json:"position"`
+	Note            string       `json:"note,omitempty"`
+}
+
+// moonAltitude returns the Moon's topocentric altitude at t, without
+// refraction. The parallax correction matters: it can lower the Moon by
+// up to a degree.
+func moonAltitude(t time.Time, lat, lon float64) (alt, az, distance float64) {
+	pos := moonEquatorial(t)
+	alt, az = horizontal(pos, t, lat, lon)
+	return alt - horizontalParallax(pos.Distance)*cosd(alt), az, pos.Distance
+}
+
+// moonRiseAltitude returns the Moon's geocentric altitude at t relative to
+// the altitude at which its upper limb touches the horizon, allowing for
+// parallax, semidiameter and refraction (Meeus ch. 15).
+func moonRiseAltitude(t time.Time, lat, lon float64) float64 {
+	pos := moonEquatorial(t)
+	alt, _ := horizontal(pos, t, lat, lon)
+	return alt - (0.7275*horizontalParallax(pos.Distance) - 0.5667)
+}
+
+func formatLocal(t time.Time, loc *time.Location) string {
+	return t.Round(time.Minute).In(loc).Format(time.RFC3339)
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// Tool handler
+
+func getMoonTimes(_ context.Context, _ *mcp.CallToolRequest, input GetMoonTimesInput) (*mcp.CallToolResult, MoonTimesOutput, error) {
+	log.Printf("[DEBUG] get_moon_times tool called with input: latitude=%.4f, longitude=%.4f, date=%s, time=%s, timezone=%s",
+		input.Latitude, input.Longitude, input.Date, input.Time, input.Timezone)
+
+	if err := coords.Validate(input.Latitude, input.Longitude); err != nil {
+		return nil, MoonTimesOutput{}, err
+	}
+	loc, err := timezone.Load(input.Timezone)
+	if err != nil {
+		return nil, MoonTimesOutput{}, err
+	}
+	t, err := parseLocalInstant(input.Date, input.Time, loc)
+	if err != nil {
+		return nil, MoonTimesOutput{}, err
+	}
+	if err := validateYear(t.Year()); err != nil {
+		return nil, MoonTimesOutput{}, err
+	}
+
+	lat, lon := input.Latitude, input.Longitude
+	dayStart, dayEnd := localDay(t)
+	rises, sets, transits := riseSetTransit(
+		func(t time.Time) float64 { return moonRiseAltitude(t, lat, lon) },
+		func(t time.Time) float64 { return hourAngle(moonEquatorial(t), t, lon) },
+		dayStart, dayEnd,
+	)
+
+	alt, az, distance := moonAltitude(t, lat, lon)
+	result := MoonTimesOutput{
+		Latitude:  lat,
+		Longitude: lon,
+		Date:      dayStart.Format("2006-01-02"),
+		Timezone:  loc.String(),
+		Position: MoonPosition{
+			Time:         t.Format(time.RFC3339),
+			Altitude:     round1(alt),
+			Azimuth:      round1(az),
+			DistanceKm:   math.Round(distance),
+			Illumination: round1(moonState(t).Illumination * 100),
+		},
+	}
+	// The Moon rises about 50 minutes later each day, so each event happens
+	// at most once per day outside polar latitudes
+	if len(rises) > 0 {
+		result.Moonrise = formatLocal(rises[0], loc)
+	}
+	if len(sets) > 0 {
+		result.Moonset = formatLocal(sets[0], loc)
+	}
+	if len(transits) > 0 {
+		result.Transit = formatLocal(transits[0], loc)
+		transitAlt, _, _ := moonAltitude(transits[0], lat, lon)
+		transitAlt = round1(transitAlt)
+		result.TransitAltitude = &transitAlt
+	}
+
+	switch {
+	case len(rises) == 0 && len(sets) == 0:
+		if moonRiseAltitude(dayStart, lat, lon) > 0 {
+			result.Note = "The Moon stays above the horizon all day"
+		} else {
+			result.Note = "The Moon stays below the horizon all day"
+		}
+	case len(rises) == 0:
+		result.Note = "The Moon does not rise on this date"
+	case len(sets) == 0:
+		result.Note = "The Moon does not set on this date"
+	}
+
+	log.Printf("[DEBUG] Moon times calculated: rise=%s, set=%s, transit=%s, altitude=%.1f, azimuth=%.1f",
+		result.Moonrise, result.Moonset, result.Transit, result.Position.Altitude, result.Position.Azimuth)
+
+	return nil, result, nil
+}
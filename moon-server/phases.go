@@ -0,0 +1,174 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// lunarPhase identifies one of the four principal phases. Its value is the
+// fraction of a lunation after new moon at which the phase occurs, times 4.
+type lunarPhase int
+
+const (
+	newMoon lunarPhase = iota
+	firstQuarter
+	fullMoon
+	lastQuarter
+)
+
+var phaseNames = [...]string{"New Moon", "First Quarter", "Full Moon", "Last Quarter"}
+var phaseEmojis = [...]string{"🌑", "🌓", "🌕", "🌗"}
+
+func (p lunarPhase) String() string { return phaseNames[p] }
+
+// phaseEvent is the instant a principal phase occurs.
+type phaseEvent struct {
+	Phase lunarPhase
+	Time  time.Time // UTC
+}
+
+// phaseInstant returns the instant of the phase identified by k, where the
+// integer part counts lunations since the new moon of 2000-01-06 and the
+// fractional part (0, .25, .5, .75) selects the phase (Meeus ch. 49). The
+// result is accurate to well under a minute for 1900–2100.
+func phaseInstant(k float64) time.Time {
+	T := k / 1236.85
+	T2, T3, T4 := T*T, T*T*T, T*T*T*T
+
+	jde := 2451550.09766 + synodicMonth*k + 0.00015437*T2 - 0.000000150*T3 + 0.00000000073*T4
+
+	E := 1 - 0.002516*T - 0.0000074*T2
+	M := 2.5534 + 29.10535670*k - 0.0000014*T2 - 0.00000011*T3
+	Mp := 201.5643 + 385.81693528*k + 0.0107582*T2 + 0.00001238*T3 - 0.000000058*T4
+	F := 160.7108 + 390.67050284*k - 0.0016118*T2 - 0.00000227*T3 + 0.000000011*T4
+	Om := 124.7746 - 1.56375588*k + 0.0020672*T2 + 0.00000215*T3
+
+	phase := phaseOf(k)
+	var corr float64
+	switch phase {
+	case newMoon:
+		corr = -0.40720*sind(Mp) + 0.17241*E*sind(M) + 0.01608*sind(2*Mp) +
+			0.01039*sind(2*F) + 0.00739*E*sind(Mp-M) - 0.00514*E*sind(Mp+M) +
+			0.00208*E*E*sind(2*M) - 0.00111*sind(Mp-2*F) - 0.00057*sind(Mp+2*F) +
+			0.00056*E*sind(2*Mp+M) - 0.00042*sind(3*Mp) + 0.00042*E*sind(M+2*F) +
+			0.00038*E*sind(M-2*F) - 0.00024*E*sind(2*Mp-M) - 0.00017*sind(Om) -
+			0.00007*sind(Mp+2*M) + 0.00004*sind(2*Mp-2*F) + 0.00004*sind(3*M) +
+			0.00003*sind(Mp+M-2*F) + 0.00003*sind(2*Mp+2*F) - 0.00003*sind(Mp+M+2*F) +
+			0.00003*sind(Mp-M+2*F) - 0.00002*sind(Mp-M-2*F) - 0.00002*sind(3*Mp+M) +
+			0.00002*sind(4*Mp)
+	case fullMoon:
+		corr = -0.40614*sind(Mp) + 0.17302*E*sind(M) + 0.01614*sind(2*Mp) +
+			0.01043*sind(2*F) + 0.00734*E*sind(Mp-M) - 0.00515*E*sind(Mp+M) +
+			0.00209*E*E*sind(2*M) - 0.00111*sind(Mp-2*F) - 0.00057*sind(Mp+2*F) +
+			0.00056*E*sind(2*Mp+M) - 0.00042*sind(3*Mp) + 0.00042*E*sind(M+2*F) +
+			0.00038*E*sind(M-2*F) - 0.00024*E*sind(2*Mp-M) - 0.00017*sind(Om) -
+			0.00007*sind(Mp+2*M) + 0.00004*sind(2*Mp-2*F) + 0.00004*sind(3*M) +
+			0.00003*sind(Mp+M-2*F) + 0.00003*sind(2*Mp+2*F) - 0.00003*sind(Mp+M+2*F) +
+			0.00003*sind(Mp-M+2*F) - 0.00002*sind(Mp-M-2*F) - 0.00002*sind(3*Mp+M) +
+			0.00002*sind(4*Mp)
+	case firstQuarter, lastQuarter:
+		corr = -0.62801*sind(Mp) + 0.17172*E*sind(M) - 0.01183*E*sind(Mp+M) +
+			0.00862*sind(2*Mp) + 0.00804*sind(2*F) + 0.00454*E*sind(Mp-M) +
+			0.00204*E*E*sind(2*M) - 0.00180*sind(Mp-2*F) - 0.00070*sind(Mp+2*F) -
+			0.00040*sind(3*Mp) - 0.00034*E*sind(2*Mp-M) + 0.00032*E*sind(M+2*F) +
+			0.00032*E*sind(M-2*F) - 0.00028*E*E*sind(Mp+2*M) + 0.00027*E*sind(2*Mp+M) -
+			0.00017*sind(Om) - 0.00005*sind(Mp-M-2*F) + 0.00004*sind(2*Mp+2*F) -
+			0.00004*sind(Mp+M+2*F) + 0.00004*sind(Mp-2*M) + 0.00003*sind(Mp+M-2*F) +
+			0.00003*sind(3*M) + 0.00002*sind(2*Mp-2*F) + 0.00002*sind(Mp-M+2*F) -
+			0.00002*sind(3*Mp+M)
+
+		W := 0.00306 - 0.00038*E*cosd(M) + 0.00026*cosd(Mp) - 0.00002*cosd(Mp-M) +
+			0.00002*cosd(Mp+M) + 0.00002*cosd(2*F)
+		if phase == firstQuarter {
+			corr += W
+		} else {
+			corr -= W
+		}
+	}
+
+	// Planetary perturbations, common to all phases
+	planetary := [...]struct{ amp, base, rate float64 }{
+		{0.000325, 299.77, 0.107408},
+		{0.000165, 251.88, 0.016321},
+		{0.000164, 251.83, 26.651886},
+		{0.000126, 349.42, 36.412478},
+		{0.000110, 84.66, 18.206239},
+		{0.000062, 141.74, 53.303771},
+		{0.000060, 207.14, 2.453732},
+		{0.000056, 154.84, 7.306860},
+		{0.000047, 34.52, 27.261239},
+		{0.000042, 207.19, 0.121824},
+		{0.000040, 291.34, 1.844379},
+		{0.000037, 161.72, 24.198154},
+		{0.000035, 239.56, 25.513099},
+		{0.000023, 331.55, 3.592518},
+	}
+	for i, p := range planetary {
+		arg := p.base + p.rate*k
+		if i == 0 {
+			arg -= 0.009173 * T2
+		}
+		corr += p.amp * sind(arg)
+	}
+
+	jde += corr
+	return timeFromJulianDay(jde - deltaT(decimalYear(jde))/86400)
+}
+
+// phaseOf returns the phase selected by the fractional part of k.
+func phaseOf(k float64) lunarPhase {
+	return lunarPhase(math.Round((k-math.Floor(k))*4)) % 4
+}
+
+// lunationNumber returns the number of lunations from the new moon of
+// 2000-01-06 to t, rounded down.
+func lunationNumber(t time.Time) float64 {
+	return math.Floor((julianDay(t) - 2451550.09766) / synodicMonth)
+}
+
+// phaseEvents returns every principal phase in [start, end), in order.
+func phaseEvents(start, end time.Time) []phaseEvent {
+	events := []phaseEvent{}
+	// Start one lunation early: the mean lunation count can be off by a
+	// fraction of a phase from the true instants.
+	for k := lunationNumber(start) - 1; ; k += 0.25 {
+		instant := phaseInstant(k)
+		if !instant.Before(end) {
+			return events
+		}
+		if !instant.Before(start) {
+			events = append(events, phaseEvent{
+				Phase: phaseOf(k),
+				Time:  instant,
+			})
+		}
+	}
+}
+
+// nextPhase returns the first occurrence of phase strictly after t.
+func nextPhase(t time.Time, phase lunarPhase) time.Time {
+	for k := lunationNumber(t) - 1 + float64(phase)/4; ; k++ {
+		if instant := phaseInstant(k); instant.After(t) {
+			return instant
+		}
+	}
+}
+
+// phaseOnDay returns the principal phase occurring during the calendar day
+// containing t in t's location, if any.
+func phaseOnDay(t time.Time) (phaseEvent, bool) {
+	events := phaseEvents(localDay(t))
+	if len(events) == 0 {
+		return phaseEvent{}, false
+	}
+	return events[0], true
+}
+
+// previousPhase returns the last occurrence of phase strictly before t.
+func previousPhase(t time.Time, phase lunarPhase) time.Time {
+	for k := lunationNumber(t) + 1 + float64(phase)/4; ; k-- {
+		if instant := phaseInstant(k); instant.Before(t) {
+			return instant
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/coords"
+	"shared/timezone"
+)
+
+// Tool input/output types
+
+type GetSunTimesInput struct {
+	Latitude  float64 `json:"latitude" jsonschema:"latitude coordinate (-90 to 90)"`
+	Longitude float64 `json:"longitude" jsonschema:"longitude coordinate (-180 to 180)"`
+	Date      string  `json:"date,omitempty" jsonschema:"local date in YYYY-MM-DD format, defaults to today"`
+	Timezone  string  `json:"timezone,omitempty" jsonschema:"IANA timezone name for date and results, e.g. Europe/Berlin; defaults to UTC"`
+}
+
+type SunTimesOutput struct {
+	Latitude          float64  `json:"latitude"`
+	Longitude         float64  `json:"longitude"`
+	Date              string   `json:"date"`
+	Timezone          string   `json:"timezone"`
+	Sunrise           string   `json:"sunrise,omitempty"`
+	Sunset            string   `json:"sunset,omitempty"`
+	SolarNoon         string   `json:"solar_noon,omitempty"`
+	SolarNoonAltitude *float64 `json:"solar_noon_altitude,omitempty"`
+	DayLengthMinutes  int      `json:"day_length_minutes"`
+	CivilDawn         string   `json:"civil_dawn,omitempty"`
+	CivilDusk         string   `json:"civil_dusk,omitempty"`
+	NauticalDawn      string   `json:"nautical_dawn,omitempty"`
+	NauticalDusk      string   `json:"nautical_dusk,omitempty"`
+	AstronomicalDawn  string   `json:"astronomical_dawn,omitempty"`
+	AstronomicalDusk  string   `json:"astronomical_dusk,omitempty"`
+	PolarDay          bool     `json:"polar_day,omitempty"`
+	PolarNight        bool     `json:"polar_night,omitempty"`
+}
+
+// Solar altitudes (degrees) defining sunrise/sunset and the twilights.
+// Sunrise allows for refraction and the Sun's semidiameter.
+const (
+	sunriseAltitude      = -0.8333
+	civilAltitude        = -6
+	nauticalAltitude     = -12
+	astronomicalAltitude = -18
+)
+
+// sunAltitude returns the Sun's geocentric altitude and azimuth at t.
+func sunAltitude(t time.Time, lat, lon float64) (alt, az float64) {
+	return horizontal(sunEquatorial(t), t, lat, lon)
+}
+
+// sunCrossings returns the first instants during [start, end) at which the
+// Sun rises above and sets below the given altitude, or zero times if it
+// does not.
+func sunCrossings(lat, lon, altitude float64, start, end time.Time) (rise, set time.Time) {
+	for _, c := range findCrossings(func(t time.Time) float64 {
+		alt, _ := sunAltitude(t, lat, lon)
+		return alt - altitude
+	}, start, end) {
+		if c.Rising && rise.IsZero() {
+			rise = c.Time
+		}
+		if !c.Rising && set.IsZero() {
+			set = c.Time
+		}
+	}
+	return rise, set
+}
+
+// formatOptional formats t like formatLocal, or returns "" for a zero time.
+func formatOptional(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+	return formatLocal(t, loc)
+}
+
+// Tool handler
+
+func getSunTimes(_ context.Context, _ *mcp.CallToolRequest, input GetSunTimesInput) (*mcp.CallToolResult, SunTimesOutput, error) {
+	log.Printf("[DEBUG] get_sun_times tool called with input: latitude=%.4f, longitude=%.4f, date=%s, timezone=%s",
+		input.Latitude, input.Longitude, input.Date, input.Timezone)
+
+	if err := coords.Validate(input.Latitude, input.Longitude); err != nil {
+		return nil, SunTimesOutput{}, err
+	}
+	loc, err := timezone.Load(input.Timezone)
+	if err != nil {
+		return nil, SunTimesOutput{}, err
+	}
+	t, err := parseLocalInstant(input.Date, "", loc)
+	if err != nil {
+		return nil, SunTimesOutput{}, err
+	}
+	if err := validateYear(t.Year()); err != nil {
+		return nil, SunTimesOutput{}, err
+	}
+
+	lat, lon := input.Latitude, input.Longitude
+	dayStart, dayEnd := localDay(t)
+
+	var noon time.Time
+	for _, c := range findCrossings(func(t time.Time) float64 {
+		return hourAngle(sunEquatorial(t), t, lon)
+	}, dayStart, dayEnd) {
+		if c.Rising {
+			noon = c.Time
+			break
+		}
+	}
+
+	result := SunTimesOutput{
+		Latitude:  lat,
+		Longitude: lon,
+		Date:      dayStart.Format("2006-01-02"),
+		Timezone:  loc.String(),
+	}
+	// With a timezone far from the coordinates' own, the transit can fall
+	// near local midnight and outside the day, or in an hour skipped by a
+	// clock change
+	if noon.IsZero() {
+		log.Printf("[DEBUG] No solar noon between %s and %s", dayStart.Format(time.RFC3339), dayEnd.Format(time.RFC3339))
+	} else {
+		noonAltitude, _ := sunAltitude(noon, lat, lon)
+		altitude := round1(noonAltitude)
+		result.SolarNoon = formatLocal(noon, loc)
+		result.SolarNoonAltitude = &altitude
+	}
+
+	rise, set := sunCrossings(lat, lon, sunriseAltitude, dayStart, dayEnd)
+	result.Sunrise = formatOptional(rise, loc)
+	result.Sunset = formatOptional(set, loc)
+
+	// Day length is the time the Sun spends above the horizon during the
+	// local day, which also covers days with only a sunrise or a sunset
+	// near the edge of polar day or night.
+	startAlt, _ := sunAltitude(dayStart, lat, lon)
+	switch {
+	case rise.IsZero() && set.IsZero():
+		if startAlt > sunriseAltitude {
+			result.PolarDay = true
+			result.DayLengthMinutes = int(math.Round(dayEnd.Sub(dayStart).Minutes()))
+		} else {
+			result.PolarNight = true
+		}
+	case rise.IsZero():
+		result.DayLengthMinutes = int(math.Round(set.Sub(dayStart).Minutes()))
+	case set.IsZero():
+		result.DayLengthMinutes = int(math.Round(dayEnd.Sub(rise).Minutes()))
+	case set.After(rise):
+		result.DayLengthMinutes = int(math.Round(set.Sub(rise).Minutes()))
+	default:
+		// Set before rise: the Sun is up at both ends of the day
+		result.DayLengthMinutes = int(math.Round(dayEnd.Sub(dayStart).Minutes() - rise.Sub(set).Minutes()))
+	}
+
+	dawn, dusk := sunCrossings(lat, lon, civilAltitude, dayStart, dayEnd)
+	result.CivilDawn, result.CivilDusk = formatOptional(dawn, loc), formatOptional(dusk, loc)
+	dawn, dusk = sunCrossings(lat, lon, nauticalAltitude, dayStart, dayEnd)
+	result.NauticalDawn, result.NauticalDusk = formatOptional(dawn, loc), formatOptional(dusk, loc)
+	dawn, dusk = sunCrossings(lat, lon, astronomicalAltitude, dayStart, dayEnd)
+	result.AstronomicalDawn, result.AstronomicalDusk = formatOptional(dawn, loc), formatOptional(dusk, loc)
+
+	log.Printf("[DEBUG] Sun times calculated: sunrise=%s, sunset=%s, solar_noon=%s, day_length=%dm, polar_day=%t, polar_night=%t",
+		result.Sunrise, result.Sunset, result.SolarNoon, result.DayLengthMinutes, result.PolarDay, result.PolarNight)
+
+	return nil, result, nil
+}
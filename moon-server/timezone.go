@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// parseLocalInstant builds an instant from an optional YYYY-MM-DD date and
+// optional HH:MM[:SS] time of day, both read in loc. A missing date means
+// today in loc; a date without a time means local midnight; neither means
+// now.
+func parseLocalInstant(date, clock string, loc *time.Location) (time.Time, error) {
+	now := time.Now().In(loc)
+	if date == "" && clock == "" {
+		return now, nil
+	}
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if date != "" {
+		d, err := time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			log.Printf("[ERROR] Invalid date format: %v", err)
+			return time.Time{}, fmt.Errorf("invalid date format, use YYYY-MM-DD: %w", err)
+		}
+		day = d
+	}
+	if clock == "" {
+		return day, nil
+	}
+
+	var tod time.Time
+	var err error
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if tod, err = time.Parse(layout, clock); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		log.Printf("[ERROR] Invalid time format: %v", err)
+		return time.Time{}, fmt.Errorf("invalid time format, use HH:MM or HH:MM:SS (24-hour): %w", err)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), tod.Hour(), tod.Minute(), tod.Second(), 0, loc), nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// quoteStore holds the quote corpus and its search index. The corpus is
+// the base quotes, from the built-in list or the quotes file, with the
+// edits made through the write tools applied on top. A change swaps in a
+// new slice and index rather than modifying the old ones, so callers can
+// keep using a snapshot without holding the lock.
+type quoteStore struct {
+	mu     sync.RWMutex
+	base   []Quote
+	edits  *quoteEdits
+	quotes []Quote
+	index  *searchIndex
+}
+
+// corpus is the quote corpus used by the tools and resources. main loads
+// it from the -quotes-file flag and keeps it up to date as the file changes.
+var corpus = newQuoteStore(defaultQuotes)
+
+func newQuoteStore(quotes []Quote) *quoteStore {
+	s := &quoteStore{edits: newQuoteEdits()}
+	s.replace(quotes)
+	return s
+}
+
+func (s *quoteStore) all() []Quote {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.quotes
+}
+
+func (s *quoteStore) searchIndex() *searchIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.index
+}
+
+// baseQuotes returns the quotes the edits apply to.
+func (s *quoteStore) baseQuotes() []Quote {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.base
+}
+
+// replace swaps in new base quotes, assigning IDs to those without one,
+// and reapplies the edits to them.
+func (s *quoteStore) replace(quotes []Quote) {
+	quotes = assignQuoteIDs(quotes)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.base = quotes
+	s.rebuildLocked()
+}
+
+// rebuildLocked recomputes the corpus and index from the base quotes and
+// edits. s.mu must be held for writing.
+func (s *quoteStore) rebuildLocked() {
+	s.quotes = s.edits.apply(s.base)
+	s.index = newSearchIndex(s.quotes)
+}
+
+// quoteID derives an ID from a quote's text and author, so a quote keeps
+// its ID across restarts and replicas. A salt above zero picks an
+// alternative when the first ID is taken.
+func quoteID(q Quote, salt int) string {
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToLower(q.Text)))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ToLower(q.Author)))
+	if salt > 0 {
+		fmt.Fprintf(h, "\x00%d", salt)
+	}
+	return fmt.Sprintf("q%012x", h.Sum64()>>16)
+}
+
+// assignQuoteIDs returns a copy of quotes in which every quote without an
+// ID has one derived from its text and author.
+func assignQuoteIDs(quotes []Quote) []Quote {
+	quotes = slices.Clone(quotes)
+	taken := make(map[string]bool)
+	for _, q := range quotes {
+		if q.ID != "" {
+			taken[q.ID] = true
+		}
+	}
+	for i := range quotes {
+		if quotes[i].ID != "" {
+			continue
+		}
+		id := quoteID(quotes[i], 0)
+		for salt := 1; taken[id]; salt++ {
+			id = quoteID(quotes[i], salt)
+		}
+		quotes[i].ID = id
+		taken[id] = true
+	}
+	return quotes
+}
+
+// quotesFile is the layout of a JSON or YAML quotes file.
+type quotesFile struct {
+	Quotes []Quote `json:"quotes" yaml:"quotes"`
+}
+
+// loadQuotes reads quotes from a JSON (.json), YAML (.yaml, .yml) or CSV
+// (.csv) file and validates them. CSV files need a header row naming the
+// text, author and (optionally) id and category columns. Quotes without an
+// id get one derived from their text and author.
+func loadQuotes(path string) ([]Quote, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file quotesFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	case ".csv":
+		file.Quotes, err = parseQuotesCSV(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("unsupported quotes file type %q (must be .json, .yaml, .yml or .csv)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	if len(file.Quotes) == 0 {
+		return nil, fmt.Errorf("%s defines no quotes", filepath.Base(path))
+	}
+	seen := make(map[Quote]int)
+	ids := make(map[string]int)
+	for i := range file.Quotes {
+		q := &file.Quotes[i]
+		q.ID = strings.TrimSpace(q.ID)
+		q.Text = strings.TrimSpace(q.Text)
+		q.Author = strings.TrimSpace(q.Author)
+		q.Category = strings.ToLower(strings.TrimSpace(q.Category))
+		q.Provider = ""
+		if err := validateQuote(*q); err != nil {
+			return nil, fmt.Errorf("quote %d: %w", i+1, err)
+		}
+		key := Quote{Text: strings.ToLower(q.Text), Author: strings.ToLower(q.Author)}
+		if first, ok := seen[key]; ok {
+			return nil, fmt.Errorf("quote %d duplicates quote %d", i+1, first)
+		}
+		seen[key] = i + 1
+		if q.ID != "" {
+			if first, ok := ids[q.ID]; ok {
+				return nil, fmt.Errorf("quote %d has the same id as quote %d", i+1, first)
+			}
+			ids[q.ID] = i + 1
+		}
+	}
+	return assignQuoteIDs(file.Quotes), nil
+}
+
+// parseQuotesCSV reads quotes from CSV with a header row.
+func parseQuotesCSV(r io.Reader) ([]Quote, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{"id": -1, "text": -1, "author": -1, "category": -1}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	if columns["text"] < 0 || columns["author"] < 0 {
+		return nil, fmt.Errorf("header row must name text and author columns")
+	}
+
+	quotes := make([]Quote, 0, len(records)-1)
+	for _, record := range records[1:] {
+		q := Quote{Text: record[columns["text"]], Author: record[columns["author"]]}
+		if i := columns["id"]; i >= 0 {
+			q.ID = record[i]
+		}
+		if i := columns["category"]; i >= 0 {
+			q.Category = record[i]
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, nil
+}
+
+func validateQuote(q Quote) error {
+	if q.Text == "" {
+		return fmt.Errorf("text is required")
+	}
+	if q.Author == "" {
+		return fmt.Errorf("author is required")
+	}
+	return nil
+}
+
+// watchQuotesFile polls path every interval and calls onChange with the
+// new quotes whenever the file changes and still loads. A file that fails
+// to load is logged and the current corpus is kept.
+func watchQuotesFile(path string, interval time.Duration, onChange func([]Quote)) {
+	info, err := os.Stat(path)
+	var modTime time.Time
+	var size int64
+	if err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+	var lastErr string
+
+	for range time.Tick(interval) {
+		info, err := os.Stat(path)
+		if err != nil {
+			if err.Error() != lastErr {
+				log.Printf("[ERROR] Failed to check quotes file, keeping current quotes: %v", err)
+				lastErr = err.Error()
+			}
+			continue
+		}
+		if info.ModTime().Equal(modTime) && info.Size() == size {
+			continue
+		}
+		modTime, size = info.ModTime(), info.Size()
+
+		quotes, err := loadQuotes(path)
+		if err != nil {
+			if err.Error() != lastErr {
+				log.Printf("[ERROR] Failed to reload quotes, keeping current quotes: %v", err)
+				lastErr = err.Error()
+			}
+			continue
+		}
+		lastErr = ""
+		if slices.Equal(quotes, corpus.baseQuotes()) {
+			log.Printf("[DEBUG] Quotes file %s touched but unchanged", path)
+			continue
+		}
+		log.Printf("[DEBUG] Reloaded %d quotes from %s", len(quotes), path)
+		onChange(quotes)
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Edit log operations.
+const (
+	editAdd    = "add"
+	editUpdate = "update"
+	editDelete = "delete"
+)
+
+// quoteEdit is one record in the edit log: a quote added, a quote replaced
+// by a new version, or a quote deleted, by ID.
+type quoteEdit struct {
+	Op    string    `json:"op"`
+	ID    string    `json:"id"`
+	Quote *Quote    `json:"quote,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// The edit log is compacted once it holds at least compactMinRecords
+// records and more than compactRatio times the records needed to describe
+// the current edits.
+const (
+	compactMinRecords = 64
+	compactRatio      = 2
+)
+
+// quoteEdits is the set of changes made through the write tools, kept
+// separately from the base quotes so the quotes file can still reload
+// underneath them. Edits refer to quotes by ID; an update or delete of a
+// base quote that has since left the quotes file is kept but has no
+// effect. A deleted ID stays deleted, whether it belonged to a base quote
+// or an added one, so deleting it again can be told apart from deleting a
+// quote that never existed.
+type quoteEdits struct {
+	changed map[string]Quote // updated base quotes and added quotes
+	deleted map[string]bool  // deleted quotes
+	added   []string         // IDs of added quotes, oldest first
+
+	// Append-only JSON Lines log the edits are persisted to. With no log
+	// file, edits last until the server exits.
+	path    string
+	file    *os.File
+	records int
+	// Set when a failed write could not be cut off the log again. Later
+	// edits are refused rather than appended after the partial record.
+	broken error
+}
+
+func newQuoteEdits() *quoteEdits {
+	return &quoteEdits{changed: make(map[string]Quote), deleted: make(map[string]bool)}
+}
+
+// apply returns base with the edits applied and added quotes at the end.
+func (e *quoteEdits) apply(base []Quote) []Quote {
+	quotes := make([]Quote, 0, len(base)+len(e.added))
+	ids := make(map[string]bool, len(base))
+	for _, q := range base {
+		ids[q.ID] = true
+		if e.deleted[q.ID] {
+			continue
+		}
+		if c, ok := e.changed[q.ID]; ok {
+			q = c
+		}
+		quotes = append(quotes, q)
+	}
+	for _, id := range e.added {
+		if ids[id] {
+			// The quotes file gained a quote with this ID, which wins
+			log.Printf("[DEBUG] Added quote %s is shadowed by a quote with the same id in the quotes file", id)
+			continue
+		}
+		quotes = append(quotes, e.changed[id])
+	}
+	return quotes
+}
+
+// validate checks that edit can be recorded.
+func (edit quoteEdit) validate() error {
+	switch edit.Op {
+	case editAdd, editUpdate:
+		if edit.Quote == nil {
+			return fmt.Errorf("%s of %s has no quote", edit.Op, edit.ID)
+		}
+	case editDelete:
+	default:
+		return fmt.Errorf("unknown operation %q", edit.Op)
+	}
+	if edit.ID == "" {
+		return fmt.Errorf("%s has no id", edit.Op)
+	}
+	return nil
+}
+
+// record applies edit to the edit set.
+func (e *quoteEdits) record(edit quoteEdit) error {
+	if err := edit.validate(); err != nil {
+		return err
+	}
+	switch edit.Op {
+	case editAdd:
+		if !slices.Contains(e.added, edit.ID) {
+			e.added = append(e.added, edit.ID)
+		}
+		e.changed[edit.ID] = *edit.Quote
+	case editUpdate:
+		e.changed[edit.ID] = *edit.Quote
+	case editDelete:
+		delete(e.changed, edit.ID)
+		if i := slices.Index(e.added, edit.ID); i >= 0 {
+			e.added = slices.Delete(e.added, i, i+1)
+		}
+		e.deleted[edit.ID] = true
+	}
+	return nil
+}
+
+// isUsed reports whether id belongs to an added quote or a deleted quote,
+// so that a new quote must not reuse it.
+func (e *quoteEdits) isUsed(id string) bool {
+	_, ok := e.changed[id]
+	return ok || e.deleted[id]
+}
+
+// compacted returns the fewest records that recreate the edit set:
+// updates of base quotes and deletes by ID, then adds in order.
+func (e *quoteEdits) compacted() []quoteEdit {
+	var edits []quoteEdit
+	now := time.Now().UTC()
+	var ids []string
+	for id := range e.changed {
+		if !slices.Contains(e.added, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		q := e.changed[id]
+		edits = append(edits, quoteEdit{Op: editUpdate, ID: id, Quote: &q, Time: now})
+	}
+	ids = ids[:0]
+	for id := range e.deleted {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		edits = append(edits, quoteEdit{Op: editDelete, ID: id, Time: now})
+	}
+	for _, id := range e.added {
+		q := e.changed[id]
+		edits = append(edits, quoteEdit{Op: editAdd, ID: id, Quote: &q, Time: now})
+	}
+	return edits
+}
+
+// openEditLog replays the edit log at path, creating it if needed, and
+// appends later edits to it. A partly written last line, left by a crash
+// mid-write, is dropped; any other malformed line is an error.
+func openEditLog(path string) (*quoteEdits, error) {
+	e := newQuoteEdits()
+	e.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	incomplete := false
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var edit quoteEdit
+		err := json.Unmarshal(line, &edit)
+		if err == nil {
+			err = e.record(edit)
+		}
+		if err != nil {
+			if i == len(lines)-1 {
+				log.Printf("[ERROR] Dropping incomplete last record in %s: %v", filepath.Base(path), err)
+				incomplete = true
+				break
+			}
+			return nil, fmt.Errorf("%s line %d: %w", filepath.Base(path), i+1, err)
+		}
+		e.records++
+	}
+
+	if incomplete || e.needsCompaction() {
+		if err := e.compact(); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+	if e.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// append writes edit to the log and applies it. Without a log file it is
+// only applied. An edit that cannot be recorded is rejected before it
+// reaches the log, and a failed write is cut off again, so the log only
+// ever holds the edits that were applied.
+func (e *quoteEdits) append(edit quoteEdit) error {
+	if err := edit.validate(); err != nil {
+		return err
+	}
+	if e.file != nil {
+		if e.broken != nil {
+			return fmt.Errorf("edit log is unusable until the server restarts: %w", e.broken)
+		}
+		line, err := json.Marshal(edit)
+		if err != nil {
+			return err
+		}
+		offset, err := e.file.Seek(0, io.SeekEnd)
+		if err != nil {
+			return fmt.Errorf("failed to find end of edit log: %w", err)
+		}
+		if _, err := e.file.Write(append(line, '\n')); err != nil {
+			return e.rollback(offset, fmt.Errorf("failed to write edit log: %w", err))
+		}
+		if err := e.file.Sync(); err != nil {
+			return e.rollback(offset, fmt.Errorf("failed to sync edit log: %w", err))
+		}
+		e.records++
+	}
+	if err := e.record(edit); err != nil {
+		return err
+	}
+
+	if e.file != nil && e.needsCompaction() {
+		if err := e.compact(); err != nil {
+			// The log is still complete, just longer than it needs to be
+			log.Printf("[ERROR] Failed to compact edit log, will retry: %v", err)
+		}
+	}
+	return nil
+}
+
+// rollback cuts the log back to offset after a failed write and returns
+// err. If the log cannot be cut, it is marked broken.
+func (e *quoteEdits) rollback(offset int64, err error) error {
+	if terr := e.file.Truncate(offset); terr != nil {
+		log.Printf("[ERROR] Failed to remove partial record from edit log %s: %v", e.path, terr)
+		e.broken = terr
+		return err
+	}
+	if _, serr := e.file.Seek(offset, io.SeekStart); serr != nil {
+		log.Printf("[ERROR] Failed to seek in edit log %s: %v", e.path, serr)
+		e.broken = serr
+	}
+	return err
+}
+
+func (e *quoteEdits) needsCompaction() bool {
+	return e.records >= compactMinRecords && e.records > compactRatio*len(e.compacted())
+}
+
+// compact replaces the log with the compacted records. The new log is
+// written beside the old one and renamed over it, so a crash leaves one
+// or the other intact.
+func (e *quoteEdits) compact() error {
+	edits := e.compacted()
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, edit := range edits {
+		if err := enc.Encode(edit); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(e.path), "."+filepath.Base(e.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), e.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(e.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if e.file != nil {
+		e.file.Close()
+	}
+	log.Printf("[DEBUG] Compacted edit log %s from %d to %d records", e.path, e.records, len(edits))
+	e.file, e.records = file, len(edits)
+	return nil
+}
+
+// useEditLog replaces the store's edits with those in the log at path.
+func (s *quoteStore) useEditLog(path string) error {
+	edits, err := openEditLog(path)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.edits = edits
+	s.rebuildLocked()
+	return nil
+}
+
+// Limits for quotes written through the tools.
+const (
+	maxQuoteText     = 1000
+	maxQuoteAuthor   = 200
+	maxQuoteCategory = 50
+)
+
+// normalizeQuote trims a quote and lowercases its category, as loadQuotes
+// does, and checks it is fit to store.
+func normalizeQuote(q Quote) (Quote, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	q.Author = strings.TrimSpace(q.Author)
+	q.Category = strings.ToLower(strings.TrimSpace(q.Category))
+	q.Provider = ""
+	if err := validateQuote(q); err != nil {
+		return Quote{}, err
+	}
+	switch {
+	case len([]rune(q.Text)) > maxQuoteText:
+		return Quote{}, fmt.Errorf("text is longer than %d characters", maxQuoteText)
+	case len([]rune(q.Author)) > maxQuoteAuthor:
+		return Quote{}, fmt.Errorf("author is longer than %d characters", maxQuoteAuthor)
+	case len([]rune(q.Category)) > maxQuoteCategory:
+		return Quote{}, fmt.Errorf("category is longer than %d characters", maxQuoteCategory)
+	}
+	return q, nil
+}
+
+// duplicateLocked returns the ID of a quote other than q with the same
+// text and author, ignoring case. s.mu must be held.
+func (s *quoteStore) duplicateLocked(q Quote) (string, bool) {
+	for _, other := range s.quotes {
+		if other.ID != q.ID && strings.EqualFold(other.Text, q.Text) && strings.EqualFold(other.Author, q.Author) {
+			return other.ID, true
+		}
+	}
+	return "", false
+}
+
+// findLocked returns the quote with id. s.mu must be held.
+func (s *quoteStore) findLocked(id string) (Quote, bool) {
+	for _, q := range s.quotes {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return Quote{}, false
+}
+
+// add stores a new quote under a fresh ID and returns it.
+func (s *quoteStore) add(q Quote) (Quote, error) {
+	q, err := normalizeQuote(q)
+	if err != nil {
+		return Quote{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, ok := s.duplicateLocked(q); ok {
+		return Quote{}, fmt.Errorf("quote already exists with id %s", id)
+	}
+
+	taken := func(id string) bool {
+		if s.edits.isUsed(id) {
+			return true
+		}
+		_, ok := s.findLocked(id)
+		return ok || slices.ContainsFunc(s.base, func(b Quote) bool { return b.ID == id })
+	}
+	q.ID = quoteID(q, 0)
+	for salt := 1; taken(q.ID); salt++ {
+		q.ID = quoteID(q, salt)
+	}
+
+	if err := s.edits.append(quoteEdit{Op: editAdd, ID: q.ID, Quote: &q, Time: time.Now().UTC()}); err != nil {
+		return Quote{}, err
+	}
+	s.rebuildLocked()
+	return q, nil
+}
+
+// update replaces the quote with id by the result of change, keeping its
+// ID, and returns the new version.
+func (s *quoteStore) update(id string, change func(*Quote)) (Quote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.findLocked(id)
+	if !ok {
+		if s.edits.deleted[id] {
+			return Quote{}, fmt.Errorf("quote %s has been deleted", id)
+		}
+		return Quote{}, fmt.Errorf("no quote with id %s", id)
+	}
+	q := old
+	change(&q)
+	q, err := normalizeQuote(q)
+	if err != nil {
+		return Quote{}, err
+	}
+	if dup, ok := s.duplicateLocked(q); ok {
+		return Quote{}, fmt.Errorf("quote already exists with id %s", dup)
+	}
+	// Repeating an update leaves nothing to change or log
+	if q == old {
+		return q, nil
+	}
+
+	if err := s.edits.append(quoteEdit{Op: editUpdate, ID: id, Quote: &q, Time: time.Now().UTC()}); err != nil {
+		return Quote{}, err
+	}
+	s.rebuildLocked()
+	return q, nil
+}
+
+// delete removes the quote with id and returns it. Deleting a quote that
+// has already been deleted succeeds without a change and reports false.
+func (s *quoteStore) delete(id string) (Quote, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.findLocked(id)
+	if !ok {
+		if s.edits.deleted[id] {
+			return Quote{}, false, nil
+		}
+		return Quote{}, false, fmt.Errorf("no quote with id %s", id)
+	}
+	if err := s.edits.append(quoteEdit{Op: editDelete, ID: id, Time: time.Now().UTC()}); err != nil {
+		return Quote{}, false, err
+	}
+	s.rebuildLocked()
+	return q, true, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// editDistance returns the optimal string alignment distance between a and
+// b: the number of single-character insertions, deletions, substitutions
+// and adjacent transpositions needed to turn a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// Three rows of the dynamic programming table are enough
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
+
+// maxEdits is the largest edit distance tolerated for a word: none for
+// very short words, where almost anything is one edit away, and two for
+// long ones.
+func maxEdits(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	}
+	return 2
+}
+
+// closestMatches returns the candidates nearest to word, if any are within
+// maxEdits of it, in candidate order.
+func closestMatches(word string, candidates []string) []string {
+	best := maxEdits(word) + 1
+	n := utf8.RuneCountInString(word)
+	var matches []string
+	for _, c := range candidates {
+		// Lengths in runes, as editDistance counts them, bound the distance
+		if abs(utf8.RuneCountInString(c)-n) >= best {
+			continue
+		}
+		switch d := editDistance(word, c); {
+		case d < best:
+			best = d
+			matches = []string{c}
+		case d == best:
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// didYouMean formats suggestions for an error message.
+func didYouMean(suggestions []string) string {
+	switch len(suggestions) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf(" (did you mean %q?)", suggestions[0])
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf(" (did you mean %s or %s?)", strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1])
+}
+
+// resolveCategory returns the category matching name, ignoring case. A
+// misspelled name resolves to the closest category when exactly one is
+// near enough; otherwise the error suggests the candidates.
+func resolveCategory(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var names []string
+	for _, c := range quoteCategories() {
+		names = append(names, c.Name)
+	}
+	if slices.Contains(names, name) {
+		return name, nil
+	}
+
+	matches := closestMatches(name, names)
+	if len(matches) == 1 {
+		log.Printf("[DEBUG] Unknown category %s, using closest match %s", name, matches[0])
+		return matches[0], nil
+	}
+	log.Printf("[ERROR] No quotes found for category: %s, suggestions: %v", name, matches)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no quotes found for category: %s (available: %s)", name, strings.Join(names, ", "))
+	}
+	return "", fmt.Errorf("no quotes found for category: %s%s", name, didYouMean(matches))
+}
+
+// correctQuery replaces each query word that matches nothing in the index,
+// even after stemming, with the closest indexed word, preferring the most
+// common one on ties. It reports whether any word changed.
+func (ix *searchIndex) correctQuery(clauses [][]string) ([][]string, bool) {
+	changed := false
+	corrected := make([][]string, len(clauses))
+	for i, clause := range clauses {
+		corrected[i] = slices.Clone(clause)
+		for j, word := range clause {
+			if ix.hasTerm(stem(word)) {
+				continue
+			}
+			matches := closestMatches(word, ix.words)
+			if len(matches) == 0 {
+				continue
+			}
+			corrected[i][j] = slices.MaxFunc(matches, func(a, b string) int {
+				return ix.wordFreq[a] - ix.wordFreq[b]
+			})
+			changed = true
+		}
+	}
+	return corrected, changed
+}
+
+// hasTerm reports whether any field contains the stemmed term.
+func (ix *searchIndex) hasTerm(term string) bool {
+	for f := range ix.fields {
+		if _, ok := ix.fields[f].postings[term]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatQuery turns parsed clauses back into a query string.
+func formatQuery(clauses [][]string) string {
+	parts := make([]string, len(clauses))
+	for i, clause := range clauses {
+		parts[i] = strings.Join(clause, " ")
+		if len(clause) > 1 {
+			parts[i] = `"` + parts[i] + `"`
+		}
+	}
+	return strings.Join(parts, " ")
+}
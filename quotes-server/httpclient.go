@@ -0,0 +1,11 @@
+package main
+
+import (
+	"time"
+
+	"shared/retryhttp"
+)
+
+// upstream is the client used by the tool handlers. main adjusts its retry
+// settings from command-line flags.
+var upstream = retryhttp.NewClient(5 * time.Second)
@@ -1,5 +1,7 @@
 // Quotes MCP Server
-// A simple MCP server that provides random quotes and quote search functionality.
+// A simple MCP server that provides random quotes and quote search functionality,
+// publishes its local quotes as MCP resources, and lets clients add, update
+// and delete quotes, persisted to an append-only log.
 // Uses public APIs and local fallback data.
 // Supports StreamableHTTP transport for gateway testing.
 package main
@@ -7,10 +9,10 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
 	"os"
 	"strings"
@@ -19,8 +21,9 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// Quote database (fallback when API is unavailable)
-var quotes = []Quote{
+// defaultQuotes is the built-in quote corpus, used when no quotes file is
+// configured and as the fallback when the API is unavailable.
+var defaultQuotes = []Quote{
 	{Text: "The only way to do great work is to love what you do.", Author: "Steve Jobs", Category: "motivation"},
 	{Text: "Innovation distinguishes between a leader and a follower.", Author: "Steve Jobs", Category: "innovation"},
 	{Text: "Stay hungry, stay foolish.", Author: "Steve Jobs", Category: "motivation"},
@@ -44,73 +47,107 @@ var quotes = []Quote{
 // Tool input/output types
 
 type Quote struct {
-	Text     string `json:"text"`
-	Author   string `json:"author"`
-	Category string `json:"category,omitempty"`
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	Text     string `json:"text" yaml:"text"`
+	Author   string `json:"author" yaml:"author"`
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	Provider string `json:"provider,omitempty" yaml:"-"`
 }
 
 type GetRandomQuoteInput struct {
-	Category string `json:"category,omitempty" jsonschema:"filter by category: motivation, wisdom, programming, innovation, life, courage"`
+	Category string `json:"category,omitempty" jsonschema:"filter by category, e.g. motivation, wisdom, programming; close misspellings are corrected"`
+	Seed     *int64 `json:"seed,omitempty" jsonschema:"pick reproducibly: the same seed, category and corpus always give the same quote. Only the local provider supports seeds"`
 }
 
 type SearchQuotesInput struct {
-	Query string `json:"query" jsonschema:"search term to find in quotes or author names"`
-	Limit int    `json:"limit,omitempty" jsonschema:"maximum number of results (default 5, max 10)"`
+	Query  string `json:"query" jsonschema:"words to find in quote text, author names or categories; all must match. Put a phrase in double quotes to match it exactly"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"maximum number of results per page (default 5, max 50)"`
+	Cursor string `json:"cursor,omitempty" jsonschema:"next_cursor from a previous search_quotes result with the same query, to get the next page"`
 }
 
 type SearchQuotesOutput struct {
-	Quotes []Quote `json:"quotes"`
-	Total  int     `json:"total"`
+	Quotes     []Quote `json:"quotes"`
+	Total      int     `json:"total"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	DidYouMean string  `json:"did_you_mean,omitempty"`
 }
 
+// Page sizes for search_quotes.
+const (
+	defaultSearchLimit = 5
+	maxSearchLimit     = 50
+)
+
 type ListCategoriesOutput struct {
 	Categories []string `json:"categories"`
 }
 
 // Tool handlers
 
-func getRandomQuote(_ context.Context, _ *mcp.CallToolRequest, input GetRandomQuoteInput) (*mcp.CallToolResult, Quote, error) {
+func getRandomQuote(ctx context.Context, _ *mcp.CallToolRequest, input GetRandomQuoteInput) (*mcp.CallToolResult, Quote, error) {
 	log.Printf("[DEBUG] get_random_quote tool called with input: category=%s", input.Category)
 
-	// Try to fetch from ZenQuotes API first
-	quote, err := fetchQuoteFromAPI()
-	if err == nil && input.Category == "" {
-		log.Printf("[DEBUG] Successfully fetched quote from API: author=%s", quote.Author)
+	if input.Seed != nil {
+		return seededRandomQuote(input.Category, *input.Seed)
+	}
+
+	// Ask each provider in order, skipping those that can't filter by
+	// category, until one returns a quote
+	var lastErr error
+	for _, p := range quoteProviders {
+		quote, err := p.RandomQuote(ctx, input.Category)
+		if errors.Is(err, errCategoryUnsupported) {
+			log.Printf("[DEBUG] Provider %s does not support categories, skipping", p.Name())
+			continue
+		}
+		if err != nil {
+			log.Printf("[DEBUG] Provider %s failed, trying next: %v", p.Name(), err)
+			lastErr = err
+			continue
+		}
+		quote.Provider = p.Name()
+		log.Printf("[DEBUG] Selected random quote: provider=%s, author=%s, category=%s", quote.Provider, quote.Author, quote.Category)
 		return nil, quote, nil
 	}
-	if err != nil {
-		log.Printf("[DEBUG] API fetch failed, falling back to local quotes: %v", err)
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no configured quote provider supports categories")
 	}
+	log.Printf("[ERROR] No provider returned a quote: %v", lastErr)
+	return nil, Quote{}, lastErr
+}
 
-	// Fall back to local quotes
-	var filteredQuotes []Quote
-	if input.Category != "" {
-		category := strings.ToLower(input.Category)
-		log.Printf("[DEBUG] Filtering quotes by category: %s", category)
-		for _, q := range quotes {
-			if strings.ToLower(q.Category) == category {
-				filteredQuotes = append(filteredQuotes, q)
-			}
+// seededRandomQuote asks the providers that support seeds, in order, for
+// the quote picked by seed.
+func seededRandomQuote(category string, seed int64) (*mcp.CallToolResult, Quote, error) {
+	log.Printf("[DEBUG] Using seed %d", seed)
+	var lastErr error
+	for _, p := range quoteProviders {
+		seeded, ok := p.(seededQuoteProvider)
+		if !ok {
+			log.Printf("[DEBUG] Provider %s does not support seeds, skipping", p.Name())
+			continue
 		}
-		if len(filteredQuotes) == 0 {
-			log.Printf("[ERROR] No quotes found for category: %s", input.Category)
-			return nil, Quote{}, fmt.Errorf("no quotes found for category: %s", input.Category)
+		quote, err := seeded.SeededQuote(category, seed)
+		if err != nil {
+			log.Printf("[DEBUG] Provider %s failed, trying next: %v", p.Name(), err)
+			lastErr = err
+			continue
 		}
-		log.Printf("[DEBUG] Found %d quotes in category %s", len(filteredQuotes), category)
-	} else {
-		filteredQuotes = quotes
-		log.Printf("[DEBUG] Using all %d local quotes", len(filteredQuotes))
+		quote.Provider = p.Name()
+		log.Printf("[DEBUG] Selected seeded quote: provider=%s, author=%s, category=%s", quote.Provider, quote.Author, quote.Category)
+		return nil, quote, nil
 	}
 
-	// Return random quote
-	idx := rand.Intn(len(filteredQuotes))
-	selectedQuote := filteredQuotes[idx]
-	log.Printf("[DEBUG] Selected random quote: author=%s, category=%s", selectedQuote.Author, selectedQuote.Category)
-	return nil, selectedQuote, nil
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no configured quote provider supports seeds (add local to -providers)")
+	}
+	log.Printf("[ERROR] No provider returned a seeded quote: %v", lastErr)
+	return nil, Quote{}, lastErr
 }
 
 func searchQuotes(_ context.Context, _ *mcp.CallToolRequest, input SearchQuotesInput) (*mcp.CallToolResult, SearchQuotesOutput, error) {
-	log.Printf("[DEBUG] search_quotes tool called with input: query=%s, limit=%d", input.Query, input.Limit)
+	log.Printf("[DEBUG] search_quotes tool called with input: query=%s, limit=%d, cursor=%s", input.Query, input.Limit, input.Cursor)
 
 	if input.Query == "" {
 		log.Printf("[ERROR] Query is required but was empty")
@@ -119,91 +156,81 @@ func searchQuotes(_ context.Context, _ *mcp.CallToolRequest, input SearchQuotesI
 
 	limit := input.Limit
 	if limit <= 0 {
-		limit = 5
+		limit = defaultSearchLimit
 		log.Printf("[DEBUG] Limit was <= 0, using default: %d", limit)
 	}
-	if limit > 10 {
-		limit = 10
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
 		log.Printf("[DEBUG] Limit exceeded max, capping at: %d", limit)
 	}
 
-	query := strings.ToLower(input.Query)
-	log.Printf("[DEBUG] Searching for query (case-insensitive): %s", query)
-	var results []Quote
-
-	for _, q := range quotes {
-		if strings.Contains(strings.ToLower(q.Text), query) ||
-			strings.Contains(strings.ToLower(q.Author), query) ||
-			strings.Contains(strings.ToLower(q.Category), query) {
-			results = append(results, q)
-			log.Printf("[DEBUG] Match found: author=%s, category=%s", q.Author, q.Category)
-			if len(results) >= limit {
-				break
+	clauses := parseQuery(input.Query)
+	if len(clauses) == 0 {
+		log.Printf("[ERROR] Query has no searchable words: %s", input.Query)
+		return nil, SearchQuotesOutput{}, fmt.Errorf("query has no searchable words")
+	}
+
+	index := corpus.searchIndex()
+	hits := index.search(clauses)
+	log.Printf("[DEBUG] Search for %v matched %d quotes", clauses, len(hits))
+
+	// With no exact matches, retry with misspelled words corrected
+	didYouMean := ""
+	if len(hits) == 0 {
+		if corrected, ok := index.correctQuery(clauses); ok {
+			if correctedHits := index.search(corrected); len(correctedHits) > 0 {
+				didYouMean = formatQuery(corrected)
+				log.Printf("[DEBUG] Corrected query %q to %q: %d matches", input.Query, didYouMean, len(correctedHits))
+				clauses, hits = corrected, correctedHits
 			}
 		}
 	}
 
-	log.Printf("[DEBUG] Search completed: found %d results (limit was %d)", len(results), limit)
-	return nil, SearchQuotesOutput{
-		Quotes: results,
-		Total:  len(results),
-	}, nil
+	fingerprint := index.queryFingerprint(clauses)
+	offset := 0
+	if input.Cursor != "" {
+		var err error
+		if offset, err = decodeCursor(input.Cursor, fingerprint); err != nil {
+			log.Printf("[ERROR] Rejected cursor %s: %v", input.Cursor, err)
+			return nil, SearchQuotesOutput{}, err
+		}
+	}
+
+	result := SearchQuotesOutput{Quotes: []Quote{}, Total: len(hits), DidYouMean: didYouMean}
+	for _, hit := range hits[min(offset, len(hits)):min(offset+limit, len(hits))] {
+		result.Quotes = append(result.Quotes, hit.Quote)
+		log.Printf("[DEBUG] Result: author=%s, category=%s, score=%.3f", hit.Quote.Author, hit.Quote.Category, hit.Score)
+	}
+	if offset+limit < len(hits) {
+		result.NextCursor = encodeCursor(offset+limit, fingerprint)
+	}
+
+	log.Printf("[DEBUG] Search completed: returning %d of %d results from offset %d (limit was %d)", len(result.Quotes), result.Total, offset, limit)
+	return nil, result, nil
 }
 
 func listCategories(_ context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, ListCategoriesOutput, error) {
 	log.Printf("[DEBUG] list_categories tool called")
 
-	categorySet := make(map[string]bool)
-	for _, q := range quotes {
-		if q.Category != "" {
-			categorySet[q.Category] = true
-		}
-	}
-
-	var categories []string
-	for c := range categorySet {
-		categories = append(categories, c)
+	categories := []string{}
+	for _, c := range quoteCategories() {
+		categories = append(categories, c.Name)
 	}
 
 	log.Printf("[DEBUG] Found %d categories: %v", len(categories), categories)
 	return nil, ListCategoriesOutput{Categories: categories}, nil
 }
 
-// Helper function to fetch from external API
-func fetchQuoteFromAPI() (Quote, error) {
-	log.Printf("[DEBUG] Fetching quote from ZenQuotes API...")
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get("https://zenquotes.io/api/random")
-	if err != nil {
-		log.Printf("[DEBUG] API request failed: %v", err)
-		return Quote{}, err
+// flagOrEnv returns the flag value if set, then the environment variable,
+// then the default.
+func flagOrEnv(flagValue, envName, def string) string {
+	if flagValue != "" {
+		return flagValue
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[ERROR] API returned non-OK status: %d", resp.StatusCode)
-		return Quote{}, fmt.Errorf("API returned status %d", resp.StatusCode)
+	if v := os.Getenv(envName); v != "" {
+		return v
 	}
-
-	var apiResp []struct {
-		Q string `json:"q"` // quote text
-		A string `json:"a"` // author
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		log.Printf("[ERROR] Failed to decode API response: %v", err)
-		return Quote{}, err
-	}
-
-	if len(apiResp) == 0 {
-		log.Printf("[ERROR] Empty response from API")
-		return Quote{}, fmt.Errorf("empty response from API")
-	}
-
-	log.Printf("[DEBUG] Successfully fetched quote from API: author=%s", apiResp[0].A)
-	return Quote{
-		Text:   apiResp[0].Q,
-		Author: apiResp[0].A,
-	}, nil
+	return def
 }
 
 // corsMiddleware adds CORS headers and handles preflight requests
@@ -235,10 +262,17 @@ func main() {
 	// Define command-line flags
 	portFlag := flag.String("port", "", "HTTP port to listen on (overrides QUOTES_SERVER_PORT env var)")
 	corsFlag := flag.Bool("cors", true, "Enable CORS middleware (needed for browser-based clients like mcp-inspector)")
+	upstreamAttemptsFlag := flag.Int("upstream-attempts", 3, "Maximum attempts per upstream API request, including the first")
+	quotesFileFlag := flag.String("quotes-file", "", "JSON, YAML or CSV file with the quote corpus (overrides QUOTES_FILE env var)")
+	providersFlag := flag.String("providers", "", "Comma-separated quote providers in the order get_random_quote tries them (overrides QUOTES_PROVIDERS env var, default zenquotes,local)")
+	providersConfigFlag := flag.String("providers-config", "", "YAML or JSON file defining generic HTTP quote providers (overrides QUOTES_PROVIDERS_CONFIG env var)")
+	zenQuotesURLFlag := flag.String("zenquotes-url", "", "ZenQuotes random quote URL (overrides QUOTES_ZENQUOTES_URL env var)")
+	stubAddrFlag := flag.String("stub-addr", "", "Also serve a stand-in upstream quote API from the local corpus on this address, e.g. :9090")
+	quotesLogFlag := flag.String("quotes-log", "", "JSON Lines file that add_quote, update_quote and delete_quote persist edits to (overrides QUOTES_LOG env var; without it edits are lost on exit)")
+	quotesReloadFlag := flag.Duration("quotes-reload-interval", 2*time.Second, "How often to check the quotes file for changes (0 disables hot reload)")
 	flag.Parse()
 
-	// Seed random number generator
-	rand.Seed(time.Now().UnixNano())
+	upstream.MaxAttempts = *upstreamAttemptsFlag
 
 	// Get port from command-line flag, environment, or use default
 	port := *portFlag
@@ -249,6 +283,56 @@ func main() {
 		}
 	}
 
+	// Load the quote corpus
+	quotesFile := flagOrEnv(*quotesFileFlag, "QUOTES_FILE", "")
+	if quotesFile != "" {
+		loaded, err := loadQuotes(quotesFile)
+		if err != nil {
+			log.Fatalf("[ERROR] Failed to load quotes: %v", err)
+		}
+		corpus.replace(loaded)
+		log.Printf("[DEBUG] Loaded %d quotes from %s", len(loaded), quotesFile)
+	} else {
+		log.Printf("[DEBUG] Using %d built-in quotes", len(corpus.all()))
+	}
+	if quotesLog := flagOrEnv(*quotesLogFlag, "QUOTES_LOG", ""); quotesLog != "" {
+		if err := corpus.useEditLog(quotesLog); err != nil {
+			log.Fatalf("[ERROR] Failed to open quotes log: %v", err)
+		}
+		log.Printf("[DEBUG] Applied edits from %s: %d quotes in corpus", quotesLog, len(corpus.all()))
+	} else {
+		log.Printf("[DEBUG] No quotes log configured, edits are kept in memory only")
+	}
+
+	// Configure quote providers
+	var providerConfigs []httpProviderConfig
+	if path := flagOrEnv(*providersConfigFlag, "QUOTES_PROVIDERS_CONFIG", ""); path != "" {
+		configs, err := loadProviderConfigs(path)
+		if err != nil {
+			log.Fatalf("[ERROR] Failed to load providers config: %v", err)
+		}
+		providerConfigs = configs
+		log.Printf("[DEBUG] Loaded %d HTTP provider definitions from %s", len(configs), path)
+	}
+	providerOrder := flagOrEnv(*providersFlag, "QUOTES_PROVIDERS", "zenquotes,local")
+	zenQuotesURL := flagOrEnv(*zenQuotesURLFlag, "QUOTES_ZENQUOTES_URL", defaultZenQuotesURL)
+	providers, err := buildProviders(strings.Split(providerOrder, ","), zenQuotesURL, providerConfigs)
+	if err != nil {
+		log.Fatalf("[ERROR] Invalid providers: %v", err)
+	}
+	quoteProviders = providers
+	log.Printf("[DEBUG] Quote providers in order: %s", providerOrder)
+
+	// Serve the stand-in upstream API
+	if *stubAddrFlag != "" {
+		go func() {
+			log.Printf("[DEBUG] Stub upstream API listening on %s", *stubAddrFlag)
+			if err := http.ListenAndServe(*stubAddrFlag, newStubUpstreamHandler(corpus)); err != nil {
+				log.Fatalf("[ERROR] Stub upstream API failed: %v", err)
+			}
+		}()
+	}
+
 	// Create MCP server
 	log.Printf("[DEBUG] Creating MCP server...")
 	server := mcp.NewServer(
@@ -260,11 +344,13 @@ func main() {
 	)
 	log.Printf("[DEBUG] MCP server created: name=%s, version=%s", "quotes-server", "1.0.0")
 
-	// Add tools
+	// Add tools. Only get_random_quote reaches outside the local corpus.
+	closedWorld := false
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "get_random_quote",
-			Description: "Get a random inspirational quote, optionally filtered by category.",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+			Description: "Get a random inspirational quote, optionally filtered by category, from the first configured provider that can supply one. Pass a seed for a reproducible pick from the local quotes. The provider field names the source. A misspelled category is corrected to the closest one, or the error suggests alternatives.",
 		},
 		getRandomQuote,
 	)
@@ -272,19 +358,75 @@ func main() {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "search_quotes",
-			Description: "Search for quotes by keyword in the quote text, author name, or category.",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true, OpenWorldHint: &closedWorld},
+			Description: "Search quotes by words or \"quoted phrases\" in the quote text, author name, or category. Results are ranked by relevance (author matches weigh more) and paged: pass next_cursor back as cursor for more. total counts all matches. Misspelled words are corrected when nothing matches exactly, and did_you_mean shows the corrected query.",
 		},
 		searchQuotes,
 	)
 
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_quote_of_the_day",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true, OpenWorldHint: &closedWorld},
+			Description: "Get the quote of the day from the local quotes, optionally from one category. The choice depends only on the date, so every session and server replica returns the same quote for the same day. date defaults to today in timezone (default UTC).",
+		},
+		getQuoteOfTheDay,
+	)
+
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "list_categories",
+			Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true, OpenWorldHint: &closedWorld},
 			Description: "List all available quote categories.",
 		},
 		listCategories,
 	)
-	log.Printf("[DEBUG] Tools added: get_random_quote, search_quotes, list_categories")
+
+	// Write tools. Their annotations let gateway policies tell them apart
+	// from the read-only tools above.
+	editor := &quoteEditor{store: corpus, server: server}
+	notDestructive, destructive := false, true
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "add_quote",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: &notDestructive, OpenWorldHint: &closedWorld},
+			Description: "Add a quote to the local corpus and return it with its new id. A quote with the same text and author as an existing one (ignoring case) is rejected.",
+		},
+		editor.addQuote,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "update_quote",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: true, OpenWorldHint: &closedWorld},
+			Description: "Change the text, author or category of a local quote by id. Omitted fields are kept, and the id stays the same. Repeating an update changes nothing further.",
+		},
+		editor.updateQuote,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "delete_quote",
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: true, OpenWorldHint: &closedWorld},
+			Description: "Delete a local quote by id and return it. Deleting a quote that has already been deleted succeeds with deleted set to false.",
+		},
+		editor.deleteQuote,
+	)
+	log.Printf("[DEBUG] Tools added: get_random_quote, search_quotes, get_quote_of_the_day, list_categories, add_quote, update_quote, delete_quote")
+
+	// Add resources
+	addResources(server)
+	log.Printf("[DEBUG] Resources added: %s, templates: %s, %s", categoriesURI, categoryURITemplate, authorURITemplate)
+
+	// Reload the corpus when the quotes file changes. Sessions stay
+	// connected and are told the resource list changed.
+	if quotesFile != "" && *quotesReloadFlag > 0 {
+		go watchQuotesFile(quotesFile, *quotesReloadFlag, func(loaded []Quote) {
+			corpus.replace(loaded)
+			refreshResources(server)
+		})
+		log.Printf("[DEBUG] Watching %s for changes every %s", quotesFile, *quotesReloadFlag)
+	}
 
 	// Create StreamableHTTP handler
 	log.Printf("[DEBUG] Creating StreamableHTTP handler...")
@@ -347,7 +489,8 @@ func main() {
 	log.Printf("Address: %s", addr)
 	log.Printf("Health endpoint: http://localhost%s/health", addr)
 	log.Printf("MCP endpoint: http://localhost%s/mcp", addr)
-	log.Printf("Available tools: get_random_quote, search_quotes, list_categories")
+	log.Printf("Available tools: get_random_quote, search_quotes, get_quote_of_the_day, list_categories, add_quote, update_quote, delete_quote")
+	log.Printf("Available resources: %s, %s, %s", categoriesURI, categoryURITemplate, authorURITemplate)
 	log.Printf("========================================")
 	log.Printf("[DEBUG] Starting HTTP server on %s...", addr)
 
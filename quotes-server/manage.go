@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type AddQuoteInput struct {
+	Text     string `json:"text" jsonschema:"the quote"`
+	Author   string `json:"author" jsonschema:"who said or wrote it"`
+	Category string `json:"category,omitempty" jsonschema:"category, e.g. motivation; stored in lower case"`
+}
+
+type UpdateQuoteInput struct {
+	ID       string  `json:"id" jsonschema:"id of the quote to change"`
+	Text     *string `json:"text,omitempty" jsonschema:"new text (unchanged if omitted)"`
+	Author   *string `json:"author,omitempty" jsonschema:"new author (unchanged if omitted)"`
+	Category *string `json:"category,omitempty" jsonschema:"new category (unchanged if omitted, empty to clear)"`
+}
+
+type DeleteQuoteInput struct {
+	ID string `json:"id" jsonschema:"id of the quote to delete"`
+}
+
+type DeleteQuoteOutput struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+	Quote   *Quote `json:"quote,omitempty"`
+}
+
+// quoteEditor serves the write tools. After each change it brings the
+// server's resources up to date, as a quotes file reload does.
+type quoteEditor struct {
+	store  *quoteStore
+	server *mcp.Server
+}
+
+func (e *quoteEditor) addQuote(_ context.Context, _ *mcp.CallToolRequest, input AddQuoteInput) (*mcp.CallToolResult, Quote, error) {
+	log.Printf("[DEBUG] add_quote tool called with input: author=%s, category=%s", input.Author, input.Category)
+
+	quote, err := e.store.add(Quote{Text: input.Text, Author: input.Author, Category: input.Category})
+	if err != nil {
+		log.Printf("[ERROR] Failed to add quote: %v", err)
+		return nil, Quote{}, err
+	}
+	refreshResources(e.server)
+
+	log.Printf("[DEBUG] Added quote %s: author=%s, category=%s", quote.ID, quote.Author, quote.Category)
+	return nil, quote, nil
+}
+
+func (e *quoteEditor) updateQuote(_ context.Context, _ *mcp.CallToolRequest, input UpdateQuoteInput) (*mcp.CallToolResult, Quote, error) {
+	log.Printf("[DEBUG] update_quote tool called with input: id=%s", input.ID)
+
+	if input.ID == "" {
+		log.Printf("[ERROR] ID is required but was empty")
+		return nil, Quote{}, fmt.Errorf("id is required")
+	}
+	if input.Text == nil && input.Author == nil && input.Category == nil {
+		log.Printf("[ERROR] Nothing to update for quote %s", input.ID)
+		return nil, Quote{}, fmt.Errorf("give at least one of text, author or category to change")
+	}
+
+	quote, err := e.store.update(input.ID, func(q *Quote) {
+		if input.Text != nil {
+			q.Text = *input.Text
+		}
+		if input.Author != nil {
+			q.Author = *input.Author
+		}
+		if input.Category != nil {
+			q.Category = *input.Category
+		}
+	})
+	if err != nil {
+		log.Printf("[ERROR] Failed to update quote %s: %v", input.ID, err)
+		return nil, Quote{}, err
+	}
+	refreshResources(e.server)
+
+	log.Printf("[DEBUG] Updated quote %s: author=%s, category=%s", quote.ID, quote.Author, quote.Category)
+	return nil, quote, nil
+}
+
+func (e *quoteEditor) deleteQuote(_ context.Context, _ *mcp.CallToolRequest, input DeleteQuoteInput) (*mcp.CallToolResult, DeleteQuoteOutput, error) {
+	log.Printf("[DEBUG] delete_quote tool called with input: id=%s", input.ID)
+
+	if input.ID == "" {
+		log.Printf("[ERROR] ID is required but was empty")
+		return nil, DeleteQuoteOutput{}, fmt.Errorf("id is required")
+	}
+
+	quote, deleted, err := e.store.delete(input.ID)
+	if err != nil {
+		log.Printf("[ERROR] Failed to delete quote %s: %v", input.ID, err)
+		return nil, DeleteQuoteOutput{}, err
+	}
+	if !deleted {
+		log.Printf("[DEBUG] Quote %s was already deleted", input.ID)
+		return nil, DeleteQuoteOutput{ID: input.ID}, nil
+	}
+	refreshResources(e.server)
+
+	log.Printf("[DEBUG] Deleted quote %s: author=%s, category=%s", quote.ID, quote.Author, quote.Category)
+	return nil, DeleteQuoteOutput{ID: quote.ID, Deleted: true, Quote: &quote}, nil
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// QuoteProvider is a source of random quotes. get_random_quote asks each
+// configured provider in turn until one returns a quote.
+type QuoteProvider interface {
+	// Name identifies the provider in results and logs.
+	Name() string
+	// RandomQuote returns a random quote, from category if it is not
+	// empty. Providers that cannot filter by category return
+	// errCategoryUnsupported.
+	RandomQuote(ctx context.Context, category string) (Quote, error)
+}
+
+var errCategoryUnsupported = errors.New("provider does not support categories")
+
+// seededQuoteProvider is implemented by providers that can pick a quote
+// reproducibly. When get_random_quote is given a seed it only asks these.
+type seededQuoteProvider interface {
+	// SeededQuote returns the same quote for the same seed, category and
+	// corpus.
+	SeededQuote(category string, seed int64) (Quote, error)
+}
+
+// quoteProviders is the provider order, configured in main from the
+// -providers flag.
+var quoteProviders = []QuoteProvider{
+	&zenQuotesProvider{url: defaultZenQuotesURL},
+	&localProvider{store: corpus},
+}
+
+// localProvider serves quotes from the local corpus.
+type localProvider struct {
+	store *quoteStore
+}
+
+func (p *localProvider) Name() string { return "local" }
+
+func (p *localProvider) RandomQuote(_ context.Context, category string) (Quote, error) {
+	candidates, err := p.candidates(category)
+	if err != nil {
+		return Quote{}, err
+	}
+	return candidates[rand.Intn(len(candidates))], nil
+}
+
+func (p *localProvider) SeededQuote(category string, seed int64) (Quote, error) {
+	candidates, err := p.candidates(category)
+	if err != nil {
+		return Quote{}, err
+	}
+	return candidates[rand.New(rand.NewSource(seed)).Intn(len(candidates))], nil
+}
+
+// candidates returns the local quotes in category, or all of them.
+func (p *localProvider) candidates(category string) ([]Quote, error) {
+	candidates := p.store.all()
+	if category != "" {
+		resolved, err := resolveCategory(category)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[DEBUG] Filtering quotes by category: %s", resolved)
+		var filtered []Quote
+		for _, q := range candidates {
+			if q.Category == resolved {
+				filtered = append(filtered, q)
+			}
+		}
+		candidates = filtered
+		log.Printf("[DEBUG] Found %d quotes in category %s", len(candidates), resolved)
+	} else {
+		log.Printf("[DEBUG] Using all %d local quotes", len(candidates))
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no local quotes available")
+	}
+	return candidates, nil
+}
+
+const defaultZenQuotesURL = "https://zenquotes.io/api/random"
+
+// zenQuotesProvider fetches quotes from the ZenQuotes API, which has no
+// categories.
+type zenQuotesProvider struct {
+	url string
+}
+
+func (p *zenQuotesProvider) Name() string { return "zenquotes" }
+
+func (p *zenQuotesProvider) RandomQuote(ctx context.Context, category string) (Quote, error) {
+	if category != "" {
+		return Quote{}, errCategoryUnsupported
+	}
+	log.Printf("[DEBUG] Fetching quote from ZenQuotes API...")
+
+	var apiResp []struct {
+		Q string `json:"q"` // quote text
+		A string `json:"a"` // author
+	}
+	if err := upstream.GetJSON(ctx, p.url, &apiResp); err != nil {
+		log.Printf("[DEBUG] API request failed: %v", err)
+		return Quote{}, err
+	}
+
+	if len(apiResp) == 0 {
+		log.Printf("[ERROR] Empty response from API")
+		return Quote{}, fmt.Errorf("empty response from API")
+	}
+
+	log.Printf("[DEBUG] Successfully fetched quote from API: author=%s", apiResp[0].A)
+	return Quote{
+		Text:   apiResp[0].Q,
+		Author: apiResp[0].A,
+	}, nil
+}
+
+// httpProviderConfig describes a generic JSON HTTP quote endpoint. Fields
+// are dot-separated paths into the response, such as "data.quote" or
+// "tags.0"; a path step into an array that is not an index uses the first
+// element, so "q" also works for a response like [{"q": ...}].
+type httpProviderConfig struct {
+	Name          string `json:"name" yaml:"name"`
+	URL           string `json:"url" yaml:"url"`
+	CategoryParam string `json:"category_param,omitempty" yaml:"category_param,omitempty"`
+	TextField     string `json:"text_field" yaml:"text_field"`
+	AuthorField   string `json:"author_field" yaml:"author_field"`
+	CategoryField string `json:"category_field,omitempty" yaml:"category_field,omitempty"`
+}
+
+// providersFile is the layout of a providers config file.
+type providersFile struct {
+	Providers []httpProviderConfig `json:"providers" yaml:"providers"`
+}
+
+// httpProvider fetches quotes from a configured JSON endpoint.
+type httpProvider struct {
+	config httpProviderConfig
+}
+
+func (p *httpProvider) Name() string { return p.config.Name }
+
+func (p *httpProvider) RandomQuote(ctx context.Context, category string) (Quote, error) {
+	requestURL := p.config.URL
+	if category != "" {
+		if p.config.CategoryParam == "" {
+			return Quote{}, errCategoryUnsupported
+		}
+		u, err := url.Parse(requestURL)
+		if err != nil {
+			return Quote{}, err
+		}
+		q := u.Query()
+		q.Set(p.config.CategoryParam, category)
+		u.RawQuery = q.Encode()
+		requestURL = u.String()
+	}
+	log.Printf("[DEBUG] Fetching quote from provider %s: %s", p.config.Name, requestURL)
+
+	var body any
+	if err := upstream.GetJSON(ctx, requestURL, &body); err != nil {
+		return Quote{}, err
+	}
+
+	quote := Quote{Category: strings.ToLower(category)}
+	var ok bool
+	if quote.Text, ok = lookupField(body, p.config.TextField); !ok || quote.Text == "" {
+		return Quote{}, fmt.Errorf("response has no %q field", p.config.TextField)
+	}
+	if quote.Author, ok = lookupField(body, p.config.AuthorField); !ok || quote.Author == "" {
+		return Quote{}, fmt.Errorf("response has no %q field", p.config.AuthorField)
+	}
+	if p.config.CategoryField != "" {
+		if c, ok := lookupField(body, p.config.CategoryField); ok && c != "" {
+			quote.Category = strings.ToLower(c)
+		}
+	}
+	return quote, nil
+}
+
+// lookupField follows a dot-separated path through decoded JSON and
+// returns the string or number it ends at.
+func lookupField(v any, path string) (string, bool) {
+	steps := strings.Split(path, ".")
+	for i := 0; i < len(steps); i++ {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[steps[i]]
+			if !ok {
+				return "", false
+			}
+			v = next
+		case []any:
+			if len(node) == 0 {
+				return "", false
+			}
+			index, err := strconv.Atoi(steps[i])
+			if err != nil {
+				// Not an index: look inside the first element
+				v = node[0]
+				i--
+				continue
+			}
+			if index < 0 || index >= len(node) {
+				return "", false
+			}
+			v = node[index]
+		default:
+			return "", false
+		}
+	}
+	return fieldValue(v)
+}
+
+// fieldValue formats a decoded JSON value as a field. A list, such as a
+// list of tags, yields its first element.
+func fieldValue(v any) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return strings.TrimSpace(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case []any:
+		if len(value) > 0 {
+			return fieldValue(value[0])
+		}
+	}
+	return "", false
+}
+
+// loadProviderConfigs reads generic HTTP provider definitions from a YAML
+// (.yaml, .yml) or JSON file and validates them.
+func loadProviderConfigs(path string) ([]httpProviderConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file providersFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range file.Providers {
+		if err := validateProviderConfig(c); err != nil {
+			return nil, fmt.Errorf("provider %d (%s): %w", i+1, c.Name, err)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("provider %d: duplicate name %q", i+1, c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return file.Providers, nil
+}
+
+func validateProviderConfig(c httpProviderConfig) error {
+	switch c.Name {
+	case "":
+		return fmt.Errorf("name is required")
+	case "local", "zenquotes":
+		return fmt.Errorf("name %q is reserved for a built-in provider", c.Name)
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if c.TextField == "" || c.AuthorField == "" {
+		return fmt.Errorf("text_field and author_field are required")
+	}
+	return nil
+}
+
+// buildProviders returns the providers named in order, which may be the
+// built-in local and zenquotes providers or names from configs.
+func buildProviders(order []string, zenQuotesURL string, configs []httpProviderConfig) ([]QuoteProvider, error) {
+	byName := make(map[string]httpProviderConfig)
+	for _, c := range configs {
+		byName[c.Name] = c
+	}
+
+	var providers []QuoteProvider
+	seen := make(map[string]bool)
+	for _, name := range order {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("provider %q is listed twice", name)
+		}
+		seen[name] = true
+		switch name {
+		case "local":
+			providers = append(providers, &localProvider{store: corpus})
+		case "zenquotes":
+			providers = append(providers, &zenQuotesProvider{url: zenQuotesURL})
+		default:
+			c, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown provider %q (use local, zenquotes, or a name from the providers config)", name)
+			}
+			providers = append(providers, &httpProvider{config: c})
+		}
+	}
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("at least one provider is required")
+	}
+	for name := range byName {
+		if !seen[name] {
+			log.Printf("[DEBUG] Provider %s is configured but not listed in -providers, ignoring it", name)
+		}
+	}
+	return providers, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// stubQuote is the only quote the stub upstream serves, so every provider
+// backed by it returns the same quote.
+var stubQuote = Quote{Text: "Make it work, make it right, make it fast.", Author: "Kent Beck", Category: "programming"}
+
+func newStubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(newStubUpstreamHandler(newQuoteStore([]Quote{stubQuote})))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// stubProviderConfig maps the stub's generic endpoint, whose category is
+// the first element of a tags list.
+func stubProviderConfig(srv *httptest.Server) httpProviderConfig {
+	return httpProviderConfig{
+		Name:          "stub",
+		URL:           srv.URL + "/quotes/random",
+		CategoryParam: "category",
+		TextField:     "quote.content",
+		AuthorField:   "quote.author",
+		CategoryField: "quote.tags",
+	}
+}
+
+func TestZenQuotesProvider(t *testing.T) {
+	srv := newStubServer(t)
+	p := &zenQuotesProvider{url: srv.URL + "/zenquotes/random"}
+
+	got, err := p.RandomQuote(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Quote{Text: stubQuote.Text, Author: stubQuote.Author}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := p.RandomQuote(context.Background(), "programming"); !errors.Is(err, errCategoryUnsupported) {
+		t.Errorf("with a category: got error %v, want %v", err, errCategoryUnsupported)
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	srv := newStubServer(t)
+	p := &httpProvider{config: stubProviderConfig(srv)}
+
+	for _, category := range []string{"", "Programming"} {
+		got, err := p.RandomQuote(context.Background(), category)
+		if err != nil {
+			t.Fatalf("category %q: %v", category, err)
+		}
+		if got != stubQuote {
+			t.Errorf("category %q: got %+v, want %+v", category, got, stubQuote)
+		}
+	}
+
+	// The stub answers an unknown category with 404
+	if _, err := p.RandomQuote(context.Background(), "poetry"); err == nil {
+		t.Error("unknown category: got no error")
+	}
+
+	noCategories := stubProviderConfig(srv)
+	noCategories.CategoryParam = ""
+	p = &httpProvider{config: noCategories}
+	if _, err := p.RandomQuote(context.Background(), "programming"); !errors.Is(err, errCategoryUnsupported) {
+		t.Errorf("without category_param: got error %v, want %v", err, errCategoryUnsupported)
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	var body any
+	err := json.Unmarshal([]byte(`{
+		"data": [
+			{"q": "first", "n": 3, "ratio": 2.5, "tags": ["x", "y"], "empty": []},
+			{"q": "second"}
+		],
+		"text": "  padded  "
+	}`), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"text", "padded", true},
+		{"data.0.q", "first", true},
+		{"data.1.q", "second", true},
+		{"data.q", "first", true}, // not an index: first element
+		{"data.n", "3", true},
+		{"data.ratio", "2.5", true},
+		{"data.tags", "x", true}, // a list yields its first element
+		{"data.tags.1", "y", true},
+		{"data.2.q", "", false},
+		{"data.-1.q", "", false},
+		{"data.empty", "", false},
+		{"data.missing", "", false},
+		{"text.more", "", false},
+		{"data", "", false},
+	}
+	for _, tc := range tests {
+		got, ok := lookupField(body, tc.path)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s: got %q, %v, want %q, %v", tc.path, got, ok, tc.want, tc.ok)
+		}
+	}
+
+	// A top-level array, as ZenQuotes returns
+	var list any
+	if err := json.Unmarshal([]byte(`[{"q": "zen", "a": "author"}]`), &list); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := lookupField(list, "q"); got != "zen" || !ok {
+		t.Errorf("q in a top-level array: got %q, %v", got, ok)
+	}
+}
+
+func TestBuildProviders(t *testing.T) {
+	configs := []httpProviderConfig{
+		{Name: "a", URL: "http://a.example/random", TextField: "text", AuthorField: "author"},
+		{Name: "b", URL: "http://b.example/random", TextField: "text", AuthorField: "author"},
+	}
+	providers, err := buildProviders([]string{"b", " local", "zenquotes", ""}, defaultZenQuotesURL, configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	if want := []string{"b", "local", "zenquotes"}; !slices.Equal(names, want) {
+		t.Errorf("got providers %v, want %v", names, want)
+	}
+
+	for _, order := range [][]string{{"local", "local"}, {"c"}, {" "}} {
+		if _, err := buildProviders(order, defaultZenQuotesURL, configs); err == nil {
+			t.Errorf("order %q: got no error", order)
+		}
+	}
+}
+
+// TestRandomQuoteProviderOrder checks that get_random_quote asks the
+// providers in order, skips those that cannot filter by category, and
+// names the provider that answered.
+func TestRandomQuoteProviderOrder(t *testing.T) {
+	srv := newStubServer(t)
+	noCategories := stubProviderConfig(srv)
+	noCategories.Name, noCategories.CategoryParam = "stub-nocat", ""
+	configs := []httpProviderConfig{stubProviderConfig(srv), noCategories}
+
+	saved := quoteProviders
+	t.Cleanup(func() { quoteProviders = saved })
+
+	tests := []struct {
+		order    []string
+		category string
+		want     string
+	}{
+		{[]string{"zenquotes", "stub", "local"}, "", "zenquotes"},
+		{[]string{"zenquotes", "stub", "local"}, "programming", "stub"},
+		{[]string{"stub-nocat", "zenquotes", "local"}, "programming", "local"},
+		{[]string{"stub-nocat", "stub"}, "", "stub-nocat"},
+		// The stub has no wisdom quotes, so the next provider answers
+		{[]string{"stub", "local"}, "wisdom", "local"},
+	}
+	for _, tc := range tests {
+		var err error
+		if quoteProviders, err = buildProviders(tc.order, srv.URL+"/zenquotes/random", configs); err != nil {
+			t.Fatal(err)
+		}
+		_, got, err := getRandomQuote(context.Background(), nil, GetRandomQuoteInput{Category: tc.category})
+		if err != nil {
+			t.Errorf("%v, category %q: %v", tc.order, tc.category, err)
+			continue
+		}
+		if got.Provider != tc.want {
+			t.Errorf("%v, category %q: got provider %s, want %s", tc.order, tc.category, got.Provider, tc.want)
+		}
+		if tc.category != "" && got.Category != tc.category {
+			t.Errorf("%v, category %q: got category %q", tc.order, tc.category, got.Category)
+		}
+	}
+
+	// No provider that supports categories
+	quoteProviders, _ = buildProviders([]string{"zenquotes", "stub-nocat"}, srv.URL+"/zenquotes/random", configs)
+	if _, _, err := getRandomQuote(context.Background(), nil, GetRandomQuoteInput{Category: "programming"}); err == nil {
+		t.Error("no provider with categories: got no error")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/timezone"
+)
+
+type GetQuoteOfTheDayInput struct {
+	Date     string `json:"date,omitempty" jsonschema:"day as YYYY-MM-DD (default today in timezone)"`
+	Category string `json:"category,omitempty" jsonschema:"pick from this category only; close misspellings are corrected"`
+	Timezone string `json:"timezone,omitempty" jsonschema:"IANA timezone that decides which day today is, e.g. Europe/Berlin (default UTC)"`
+}
+
+type QuoteOfTheDayOutput struct {
+	Date     string `json:"date"`
+	Timezone string `json:"timezone"`
+	Quote
+}
+
+// quoteOfTheDay picks the quote for date from quotes. Each quote is scored
+// by hashing it together with the date and the highest score wins, so the
+// choice depends only on the date and the quotes themselves: every replica
+// agrees regardless of corpus order, and adding or removing a quote only
+// changes the days that quote wins or would win.
+func quoteOfTheDay(date string, quotes []Quote) (Quote, bool) {
+	var best Quote
+	var bestScore uint64
+	found := false
+	for _, q := range quotes {
+		h := fnv.New64a()
+		h.Write([]byte(date))
+		for _, field := range []string{q.Text, q.Author, q.Category} {
+			h.Write([]byte{0})
+			h.Write([]byte(field))
+		}
+		if score := h.Sum64(); !found || score > bestScore {
+			best, bestScore, found = q, score, true
+		}
+	}
+	return best, found
+}
+
+func getQuoteOfTheDay(_ context.Context, _ *mcp.CallToolRequest, input GetQuoteOfTheDayInput) (*mcp.CallToolResult, QuoteOfTheDayOutput, error) {
+	log.Printf("[DEBUG] get_quote_of_the_day tool called with input: date=%s, category=%s, timezone=%s", input.Date, input.Category, input.Timezone)
+
+	loc, err := timezone.Load(input.Timezone)
+	if err != nil {
+		return nil, QuoteOfTheDayOutput{}, err
+	}
+
+	date := input.Date
+	if date == "" {
+		date = time.Now().In(loc).Format("2006-01-02")
+		log.Printf("[DEBUG] No date given, using today in %s: %s", loc, date)
+	} else if _, err := time.Parse("2006-01-02", date); err != nil {
+		log.Printf("[ERROR] Invalid date %q: %v", date, err)
+		return nil, QuoteOfTheDayOutput{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD", date)
+	}
+
+	candidates := corpus.all()
+	if input.Category != "" {
+		resolved, err := resolveCategory(input.Category)
+		if err != nil {
+			return nil, QuoteOfTheDayOutput{}, err
+		}
+		var filtered []Quote
+		for _, q := range candidates {
+			if q.Category == resolved {
+				filtered = append(filtered, q)
+			}
+		}
+		candidates = filtered
+	}
+
+	quote, ok := quoteOfTheDay(date, candidates)
+	if !ok {
+		log.Printf("[ERROR] No quotes available for the quote of the day")
+		return nil, QuoteOfTheDayOutput{}, fmt.Errorf("no quotes available")
+	}
+	quote.Provider = "local"
+
+	log.Printf("[DEBUG] Quote of the day for %s: author=%s, category=%s (from %d quotes)", date, quote.Author, quote.Category, len(candidates))
+	return nil, QuoteOfTheDayOutput{Date: date, Timezone: loc.String(), Quote: quote}, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Resource URIs. Names in template URIs are percent-encoded, for example
+// quotes://author/Steve%20Jobs.
+const (
+	categoriesURI       = "quotes://categories"
+	categoryURIPrefix   = "quotes://category/"
+	authorURIPrefix     = "quotes://author/"
+	categoryURITemplate = categoryURIPrefix + "{name}"
+	authorURITemplate   = authorURIPrefix + "{name}"
+)
+
+type CategorySummary struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	URI   string `json:"uri"`
+}
+
+type CategoriesResource struct {
+	Categories []CategorySummary `json:"categories"`
+}
+
+type QuoteListResource struct {
+	Category string  `json:"category,omitempty"`
+	Author   string  `json:"author,omitempty"`
+	Quotes   []Quote `json:"quotes"`
+}
+
+// categoryURI returns the resource URI for a category.
+func categoryURI(name string) string {
+	return categoryURIPrefix + url.PathEscape(name)
+}
+
+// quoteCategories returns the distinct categories of the local quotes,
+// sorted, with the number of quotes in each.
+func quoteCategories() []CategorySummary {
+	counts := make(map[string]int)
+	for _, q := range corpus.all() {
+		if q.Category != "" {
+			counts[q.Category]++
+		}
+	}
+
+	categories := make([]CategorySummary, 0, len(counts))
+	for name, count := range counts {
+		categories = append(categories, CategorySummary{Name: name, Count: count, URI: categoryURI(name)})
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+	return categories
+}
+
+// jsonResource returns a read result holding v as JSON.
+func jsonResource(uri string, v any) (*mcp.ReadResourceResult, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource %s: %w", uri, err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: uri, MIMEType: "application/json", Text: string(data)},
+		},
+	}, nil
+}
+
+// resourceName returns the unescaped {name} part of uri after prefix.
+func resourceName(uri, prefix string) (string, error) {
+	escaped, ok := strings.CutPrefix(uri, prefix)
+	if !ok || escaped == "" {
+		return "", mcp.ResourceNotFoundError(uri)
+	}
+	name, err := url.PathUnescape(escaped)
+	if err != nil {
+		log.Printf("[ERROR] Invalid escape in resource URI %s: %v", uri, err)
+		return "", mcp.ResourceNotFoundError(uri)
+	}
+	return name, nil
+}
+
+// Resource handlers
+
+func readCategories(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	log.Printf("[DEBUG] Resource read: %s", req.Params.URI)
+	return jsonResource(req.Params.URI, CategoriesResource{Categories: quoteCategories()})
+}
+
+func readCategory(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	log.Printf("[DEBUG] Resource read: %s", uri)
+
+	name, err := resourceName(uri, categoryURIPrefix)
+	if err != nil {
+		return nil, err
+	}
+	result := QuoteListResource{Category: strings.ToLower(name), Quotes: []Quote{}}
+	for _, q := range corpus.all() {
+		if strings.EqualFold(q.Category, name) {
+			result.Quotes = append(result.Quotes, q)
+		}
+	}
+	if len(result.Quotes) == 0 {
+		log.Printf("[ERROR] No quotes found for category: %s", name)
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	log.Printf("[DEBUG] Found %d quotes in category %s", len(result.Quotes), name)
+	return jsonResource(uri, result)
+}
+
+func readAuthor(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	log.Printf("[DEBUG] Resource read: %s", uri)
+
+	name, err := resourceName(uri, authorURIPrefix)
+	if err != nil {
+		return nil, err
+	}
+	result := QuoteListResource{Author: name, Quotes: []Quote{}}
+	for _, q := range corpus.all() {
+		if strings.EqualFold(q.Author, name) {
+			result.Author = q.Author
+			result.Quotes = append(result.Quotes, q)
+		}
+	}
+	if len(result.Quotes) == 0 {
+		log.Printf("[ERROR] No quotes found for author: %s", name)
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	log.Printf("[DEBUG] Found %d quotes by %s", len(result.Quotes), result.Author)
+	return jsonResource(uri, result)
+}
+
+// published is the set of per-category resource URIs registered with the
+// server. Its lock also serializes refreshes, so edits and quotes file
+// reloads that change the corpus at the same time cannot undo each other's
+// resource changes.
+var published = struct {
+	mu   sync.Mutex
+	uris map[string]bool
+}{uris: make(map[string]bool)}
+
+// addResources publishes the quote corpus as resources: the category list,
+// one resource per category, and templates for categories and authors.
+func addResources(server *mcp.Server) {
+	addCategoriesResource(server)
+	published.mu.Lock()
+	for _, c := range quoteCategories() {
+		addCategoryResource(server, c)
+		published.uris[c.URI] = true
+	}
+	published.mu.Unlock()
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: categoryURITemplate,
+		Name:        "category",
+		Title:       "Quotes by category",
+		Description: "All quotes in a category (case-insensitive).",
+		MIMEType:    "application/json",
+	}, readCategory)
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: authorURITemplate,
+		Name:        "author",
+		Title:       "Quotes by author",
+		Description: "All quotes by an author (case-insensitive, percent-encode spaces as %20).",
+		MIMEType:    "application/json",
+	}, readAuthor)
+}
+
+func addCategoriesResource(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		URI:         categoriesURI,
+		Name:        "categories",
+		Title:       "Quote categories",
+		Description: "All quote categories with the number of quotes in each.",
+		MIMEType:    "application/json",
+	}, readCategories)
+}
+
+func addCategoryResource(server *mcp.Server, c CategorySummary) {
+	server.AddResource(&mcp.Resource{
+		URI:         c.URI,
+		Name:        "category-" + c.Name,
+		Title:       fmt.Sprintf("Quotes about %s", c.Name),
+		Description: fmt.Sprintf("All quotes in the %s category.", c.Name),
+		MIMEType:    "application/json",
+	}, readCategory)
+}
+
+// refreshResources brings the per-category resources in line with the
+// corpus after it changed, comparing the current categories with the
+// resources actually registered. The server sends connected clients a
+// resources/list_changed notification for each change; the category list
+// is always re-added so that clients hear about changes to quotes within
+// existing categories too.
+func refreshResources(server *mcp.Server) {
+	published.mu.Lock()
+	defer published.mu.Unlock()
+
+	current := quoteCategories()
+	var removed []string
+	for uri := range published.uris {
+		if !slices.ContainsFunc(current, func(c CategorySummary) bool { return c.URI == uri }) {
+			removed = append(removed, uri)
+			delete(published.uris, uri)
+		}
+	}
+	if len(removed) > 0 {
+		slices.Sort(removed)
+		server.RemoveResources(removed...)
+	}
+	for _, c := range current {
+		if !published.uris[c.URI] {
+			addCategoryResource(server, c)
+			published.uris[c.URI] = true
+		}
+	}
+	addCategoriesResource(server)
+	log.Printf("[DEBUG] Resources refreshed: %d categories, %d removed", len(current), len(removed))
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// listResourceURIs returns the URIs of the resources server lists, sorted.
+func listResourceURIs(t *testing.T, server *mcp.Server) []string {
+	t.Helper()
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	res, err := cs.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uris []string
+	for _, r := range res.Resources {
+		uris = append(uris, r.URI)
+	}
+	slices.Sort(uris)
+	return uris
+}
+
+// TestRefreshResourcesFollowsCorpus checks that the per-category resources
+// match the corpus after edits and reloads in any order, including a
+// category that is emptied and then filled again.
+func TestRefreshResourcesFollowsCorpus(t *testing.T) {
+	savedCorpus, savedURIs := corpus, published.uris
+	t.Cleanup(func() { corpus, published.uris = savedCorpus, savedURIs })
+	corpus = newQuoteStore([]Quote{
+		{Text: "Brevity is the soul of wit.", Author: "William Shakespeare", Category: "wit"},
+		{Text: "Talk is cheap. Show me the code.", Author: "Linus Torvalds", Category: "programming"},
+	})
+	published.uris = make(map[string]bool)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "quotes-server", Version: "test"}, nil)
+	addResources(server)
+	editor := &quoteEditor{store: corpus, server: server}
+	ctx := context.Background()
+
+	wantURIs := func(categories ...string) []string {
+		uris := []string{categoriesURI}
+		for _, c := range categories {
+			uris = append(uris, categoryURI(c))
+		}
+		slices.Sort(uris)
+		return uris
+	}
+	check := func(step string, want []string) {
+		t.Helper()
+		if got := listResourceURIs(t, server); !slices.Equal(got, want) {
+			t.Errorf("%s: got resources %v, want %v", step, got, want)
+		}
+	}
+	check("start", wantURIs("programming", "wit"))
+
+	// Empty the wit category, then fill it again
+	witID := corpus.all()[0].ID
+	if _, _, err := editor.deleteQuote(ctx, nil, DeleteQuoteInput{ID: witID}); err != nil {
+		t.Fatal(err)
+	}
+	check("after deleting the last wit quote", wantURIs("programming"))
+	if _, _, err := editor.addQuote(ctx, nil, AddQuoteInput{Text: "Wit is educated insolence.", Author: "Aristotle", Category: "wit"}); err != nil {
+		t.Fatal(err)
+	}
+	check("after adding a wit quote", wantURIs("programming", "wit"))
+
+	// A change the refresh did not see coming: the corpus gains and loses
+	// categories without any edit
+	corpus.replace([]Quote{{Text: "Less is more.", Author: "Ludwig Mies van der Rohe", Category: "design"}})
+	refreshResources(server)
+	check("after a reload", wantURIs("design", "wit"))
+
+	// A refresh with nothing changed leaves the resources alone
+	refreshResources(server)
+	check("after a second refresh", wantURIs("design", "wit"))
+
+	// Concurrent edits that empty and refill a category end with the
+	// resources matching the corpus
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, q, err := editor.addQuote(ctx, nil, AddQuoteInput{Text: fmt.Sprintf("Burst quote %d.", i), Author: "Test", Category: "burst"})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if _, _, err := editor.deleteQuote(ctx, nil, DeleteQuoteInput{ID: q.ID}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	check("after concurrent edits", wantURIs("design", "wit"))
+}
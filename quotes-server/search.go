@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Search fields. Each field is indexed separately so matches can be
+// weighted: a hit in the author's name counts for more than one in the
+// quote text.
+const (
+	fieldText = iota
+	fieldAuthor
+	fieldCategory
+	numSearchFields
+)
+
+var fieldBoosts = [numSearchFields]float64{
+	fieldText:     1.0,
+	fieldAuthor:   2.0,
+	fieldCategory: 1.5,
+}
+
+// BM25 parameters: term frequency saturation and length normalization.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type posting struct {
+	doc       int
+	positions []int
+}
+
+type fieldIndex struct {
+	postings  map[string][]posting
+	lengths   []int
+	avgLength float64
+}
+
+// searchIndex is an inverted index over a quote corpus. It is built once
+// per corpus and never modified, so it can be searched concurrently.
+type searchIndex struct {
+	quotes []Quote
+	fields [numSearchFields]fieldIndex
+	// fingerprint identifies the corpus the index was built from, so that
+	// cursors from an older corpus can be rejected.
+	fingerprint uint64
+
+	// Vocabulary for typo correction: every unstemmed word, sorted, with
+	// the number of quotes it appears in.
+	words    []string
+	wordFreq map[string]int
+}
+
+type searchHit struct {
+	Quote Quote
+	Score float64
+}
+
+func quoteFields(q Quote) [numSearchFields]string {
+	return [numSearchFields]string{
+		fieldText:     q.Text,
+		fieldAuthor:   q.Author,
+		fieldCategory: q.Category,
+	}
+}
+
+// splitWords splits s into lowercased words. Apostrophes are dropped so
+// that "don't" and "dont" match.
+func splitWords(s string) []string {
+	s = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(s))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tokenize splits s into lowercased, stemmed words.
+func tokenize(s string) []string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = stem(w)
+	}
+	return words
+}
+
+func newSearchIndex(quotes []Quote) *searchIndex {
+	ix := &searchIndex{quotes: quotes, wordFreq: make(map[string]int)}
+	h := fnv.New64a()
+	for f := range ix.fields {
+		ix.fields[f] = fieldIndex{postings: make(map[string][]posting), lengths: make([]int, len(quotes))}
+	}
+
+	for doc, q := range quotes {
+		seen := make(map[string]bool)
+		for f, text := range quoteFields(q) {
+			fi := &ix.fields[f]
+			h.Write([]byte(text))
+			h.Write([]byte{0})
+			for pos, word := range splitWords(text) {
+				if !seen[word] {
+					seen[word] = true
+					ix.wordFreq[word]++
+				}
+				term := stem(word)
+				list := fi.postings[term]
+				if n := len(list); n > 0 && list[n-1].doc == doc {
+					list[n-1].positions = append(list[n-1].positions, pos)
+				} else {
+					list = append(list, posting{doc: doc, positions: []int{pos}})
+				}
+				fi.postings[term] = list
+				fi.lengths[doc]++
+			}
+		}
+	}
+	for f := range ix.fields {
+		fi := &ix.fields[f]
+		total := 0
+		for _, n := range fi.lengths {
+			total += n
+		}
+		if len(quotes) > 0 {
+			fi.avgLength = float64(total) / float64(len(quotes))
+		}
+	}
+	for word := range ix.wordFreq {
+		ix.words = append(ix.words, word)
+	}
+	slices.Sort(ix.words)
+	ix.fingerprint = h.Sum64()
+	return ix
+}
+
+// parseQuery splits a query into clauses of lowercased words. Text in
+// double quotes is a phrase whose words must appear next to each other, in
+// order, in one field; every other word is a clause of its own.
+func parseQuery(query string) [][]string {
+	var clauses [][]string
+	for i, part := range strings.Split(query, "\"") {
+		words := splitWords(part)
+		if len(words) == 0 {
+			continue
+		}
+		if i%2 == 1 {
+			clauses = append(clauses, words)
+			continue
+		}
+		for _, w := range words {
+			clauses = append(clauses, []string{w})
+		}
+	}
+	return clauses
+}
+
+// occurrences returns, per document, how often the phrase appears in the
+// field.
+func (fi *fieldIndex) occurrences(phrase []string) map[int]int {
+	counts := make(map[int]int)
+	if len(phrase) == 1 {
+		for _, p := range fi.postings[phrase[0]] {
+			counts[p.doc] = len(p.positions)
+		}
+		return counts
+	}
+
+	// Positions of each later word, by document
+	rest := make([]map[int][]int, len(phrase)-1)
+	for i, w := range phrase[1:] {
+		rest[i] = make(map[int][]int)
+		for _, p := range fi.postings[w] {
+			rest[i][p.doc] = p.positions
+		}
+	}
+	for _, p := range fi.postings[phrase[0]] {
+		for _, start := range p.positions {
+			match := true
+			for i := range rest {
+				if _, ok := slices.BinarySearch(rest[i][p.doc], start+i+1); !ok {
+					match = false
+					break
+				}
+			}
+			if match {
+				counts[p.doc]++
+			}
+		}
+	}
+	return counts
+}
+
+// stemClauses returns the clauses with every word stemmed.
+func stemClauses(clauses [][]string) [][]string {
+	stemmed := make([][]string, len(clauses))
+	for i, clause := range clauses {
+		stemmed[i] = make([]string, len(clause))
+		for j, w := range clause {
+			stemmed[i][j] = stem(w)
+		}
+	}
+	return stemmed
+}
+
+// search returns the quotes matching every clause, best first, scored with
+// BM25 summed over the boosted fields.
+func (ix *searchIndex) search(clauses [][]string) []searchHit {
+	n := float64(len(ix.quotes))
+	scores := make(map[int]float64)
+	for c, clause := range stemClauses(clauses) {
+		var perField [numSearchFields]map[int]int
+		docs := make(map[int]bool)
+		for f := range ix.fields {
+			perField[f] = ix.fields[f].occurrences(clause)
+			for doc := range perField[f] {
+				docs[doc] = true
+			}
+		}
+
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		next := make(map[int]float64)
+		for doc := range docs {
+			// Every clause must match
+			prev, ok := scores[doc]
+			if c > 0 && !ok {
+				continue
+			}
+			score := prev
+			for f := range ix.fields {
+				tf := float64(perField[f][doc])
+				if tf == 0 {
+					continue
+				}
+				fi := &ix.fields[f]
+				norm := 1 - bm25B + bm25B*float64(fi.lengths[doc])/fi.avgLength
+				score += fieldBoosts[f] * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+			}
+			next[doc] = score
+		}
+		scores = next
+	}
+
+	hits := make([]searchHit, 0, len(scores))
+	docs := make([]int, 0, len(scores))
+	for doc := range scores {
+		docs = append(docs, doc)
+	}
+	slices.Sort(docs)
+	for _, doc := range docs {
+		hits = append(hits, searchHit{Quote: ix.quotes[doc], Score: scores[doc]})
+	}
+	slices.SortStableFunc(hits, func(a, b searchHit) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return hits
+}
+
+// queryFingerprint identifies a parsed query against this index's corpus.
+func (ix *searchIndex) queryFingerprint(clauses [][]string) uint64 {
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, ix.fingerprint)
+	for _, clause := range stemClauses(clauses) {
+		h.Write([]byte(strings.Join(clause, " ")))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// encodeCursor returns an opaque cursor for the result page starting at
+// offset.
+func encodeCursor(offset int, fingerprint uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%x", offset, fingerprint)))
+}
+
+// decodeCursor returns the offset in cursor, or an error if the cursor is
+// malformed or was issued for a different query or corpus.
+func decodeCursor(cursor string, fingerprint uint64) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	offsetText, fp, ok := strings.Cut(string(data), ":")
+	offset, err := strconv.Atoi(offsetText)
+	if !ok || err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	if fp != strconv.FormatUint(fingerprint, 16) {
+		return 0, fmt.Errorf("cursor does not match this query or the quotes have changed; search again without a cursor")
+	}
+	return offset, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+// Porter stemmer (M. F. Porter, "An algorithm for suffix stripping", 1980),
+// following the reference C implementation. Words are expected in lower
+// case; words with characters outside a-z are returned unchanged.
+
+func isConsonant(w []byte, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !isConsonant(w, i-1)
+	}
+	return true
+}
+
+// measure returns m, the number of vowel-consonant sequences in w.
+func measure(w []byte) int {
+	m, i := 0, 0
+	for i < len(w) && isConsonant(w, i) {
+		i++
+	}
+	for i < len(w) {
+		for i < len(w) && !isConsonant(w, i) {
+			i++
+		}
+		if i == len(w) {
+			break
+		}
+		for i < len(w) && isConsonant(w, i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func hasVowel(w []byte) bool {
+	for i := range w {
+		if !isConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func endsDoubleConsonant(w []byte) bool {
+	n := len(w)
+	return n >= 2 && w[n-1] == w[n-2] && isConsonant(w, n-1)
+}
+
+// endsCVC reports whether w ends consonant-vowel-consonant, where the last
+// consonant is not w, x or y.
+func endsCVC(w []byte) bool {
+	n := len(w)
+	if n < 3 || !isConsonant(w, n-3) || isConsonant(w, n-2) || !isConsonant(w, n-1) {
+		return false
+	}
+	switch w[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func hasSuffix(w []byte, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+type suffixRule struct {
+	suffix, replacement string
+}
+
+// applyRules replaces the first matching suffix if the remaining stem has
+// a measure greater than minMeasure. Only the first match is considered.
+func applyRules(w []byte, rules []suffixRule, minMeasure int) []byte {
+	for _, r := range rules {
+		if hasSuffix(w, r.suffix) {
+			stem := w[:len(w)-len(r.suffix)]
+			if measure(stem) > minMeasure {
+				return append(stem, r.replacement...)
+			}
+			return w
+		}
+	}
+	return w
+}
+
+var step2Rules = []suffixRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"},
+	{"eli", "e"}, {"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"},
+	{"ator", "ate"}, {"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"},
+	{"ousness", "ous"}, {"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"},
+	{"logi", "log"},
+}
+
+var step3Rules = []suffixRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+// stem returns the Porter stem of word.
+func stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+	w := []byte(word)
+
+	// Step 1a: plurals
+	switch {
+	case hasSuffix(w, "sses"), hasSuffix(w, "ies"):
+		w = w[:len(w)-2]
+	case hasSuffix(w, "ss"):
+	case hasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+
+	// Step 1b: -eed, -ed, -ing
+	if hasSuffix(w, "eed") {
+		if measure(w[:len(w)-3]) > 0 {
+			w = w[:len(w)-1]
+		}
+	} else if suffix := ""; hasSuffix(w, "ed") && hasVowel(w[:len(w)-2]) || hasSuffix(w, "ing") && hasVowel(w[:len(w)-3]) {
+		suffix = "ed"
+		if hasSuffix(w, "ing") {
+			suffix = "ing"
+		}
+		w = w[:len(w)-len(suffix)]
+		switch {
+		case hasSuffix(w, "at"), hasSuffix(w, "bl"), hasSuffix(w, "iz"):
+			w = append(w, 'e')
+		case endsDoubleConsonant(w):
+			if c := w[len(w)-1]; c != 'l' && c != 's' && c != 'z' {
+				w = w[:len(w)-1]
+			}
+		case measure(w) == 1 && endsCVC(w):
+			w = append(w, 'e')
+		}
+	}
+
+	// Step 1c: y to i
+	if hasSuffix(w, "y") && hasVowel(w[:len(w)-1]) {
+		w[len(w)-1] = 'i'
+	}
+
+	w = applyRules(w, step2Rules, 0)
+	w = applyRules(w, step3Rules, 0)
+
+	// Step 4: remove suffixes when m > 1
+	for _, suffix := range step4Suffixes {
+		if hasSuffix(w, suffix) {
+			s := w[:len(w)-len(suffix)]
+			if measure(s) > 1 && (suffix != "ion" || hasSuffix(s, "s") || hasSuffix(s, "t")) {
+				w = s
+			}
+			break
+		}
+	}
+
+	// Step 5a: final e
+	if hasSuffix(w, "e") {
+		s := w[:len(w)-1]
+		if m := measure(s); m > 1 || m == 1 && !endsCVC(s) {
+			w = s
+		}
+	}
+	// Step 5b: -ll when m > 1
+	if hasSuffix(w, "ll") && measure(w) > 1 {
+		w = w[:len(w)-1]
+	}
+	return string(w)
+}
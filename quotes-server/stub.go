@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+// newStubUpstreamHandler returns a stand-in for the upstream quote APIs,
+// serving random quotes from the local corpus. It lets the zenquotes and
+// generic HTTP providers be exercised without network access, either
+// in-process with -stub-addr or from an httptest.Server in tests:
+//
+//	GET /zenquotes/random           ZenQuotes format: [{"q": ..., "a": ...}]
+//	GET /quotes/random?category=... {"quote": {"content": ..., "author": ..., "tags": [...]}}
+func newStubUpstreamHandler(store *quoteStore) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /zenquotes/random", func(w http.ResponseWriter, r *http.Request) {
+		quotes := store.all()
+		if len(quotes) == 0 {
+			http.Error(w, "no quotes", http.StatusNotFound)
+			return
+		}
+		q := quotes[rand.Intn(len(quotes))]
+		writeStubJSON(w, []map[string]string{{"q": q.Text, "a": q.Author}})
+	})
+
+	mux.HandleFunc("GET /quotes/random", func(w http.ResponseWriter, r *http.Request) {
+		category := strings.ToLower(r.URL.Query().Get("category"))
+		var candidates []Quote
+		for _, q := range store.all() {
+			if category == "" || q.Category == category {
+				candidates = append(candidates, q)
+			}
+		}
+		if len(candidates) == 0 {
+			http.Error(w, "no quotes for category", http.StatusNotFound)
+			return
+		}
+		q := candidates[rand.Intn(len(candidates))]
+		tags := []string{}
+		if q.Category != "" {
+			tags = append(tags, q.Category)
+		}
+		writeStubJSON(w, map[string]any{
+			"quote": map[string]any{"content": q.Text, "author": q.Author, "tags": tags},
+		})
+	})
+
+	return mux
+}
+
+func writeStubJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[ERROR] Stub upstream failed to write response: %v", err)
+	}
+}
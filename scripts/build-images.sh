@@ -21,7 +21,8 @@ cd "${PROJECT_DIR}"
 # Build all servers
 for SERVER in moon-server quotes-server weather-server; do
     echo "Building ${SERVER}..."
-    podman build -t "${REGISTRY}/${SERVER}:${VERSION}" "${SERVER}/"
+    # Build from the project root so the shared module is in the context
+    podman build -t "${REGISTRY}/${SERVER}:${VERSION}" -f "${SERVER}/Dockerfile" .
     echo "✓ ${SERVER} built successfully"
     echo ""
 done
@@ -0,0 +1,21 @@
+// Package coords validates the latitude and longitude the MCP servers
+// accept as tool input.
+package coords
+
+import (
+	"fmt"
+	"log"
+)
+
+// Validate checks that latitude and longitude are in range.
+func Validate(lat, lon float64) error {
+	if lat < -90 || lat > 90 {
+		log.Printf("[ERROR] Invalid latitude: %.4f (must be between -90 and 90)", lat)
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if lon < -180 || lon > 180 {
+		log.Printf("[ERROR] Invalid longitude: %.4f (must be between -180 and 180)", lon)
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	return nil
+}
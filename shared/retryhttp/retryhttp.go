@@ -0,0 +1,164 @@
+// Package retryhttp is the upstream HTTP client shared by the MCP servers
+// in this repository. Each server module requires it through a replace
+// directive pointing at ../shared.
+package retryhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Client is an HTTP client for upstream API calls. It keeps connections
+// alive between tool calls, honors the caller's context, and retries GET
+// requests on network errors, 5xx and 429 responses using jittered
+// exponential backoff.
+type Client struct {
+	// MaxAttempts is the most attempts per request, including the first.
+	MaxAttempts int
+
+	client    *http.Client
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+// NewClient returns a Client whose requests time out after timeout and
+// that makes up to three attempts per request.
+func NewClient(timeout time.Duration) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 10
+	transport.IdleConnTimeout = 90 * time.Second
+
+	return &Client{
+		MaxAttempts: 3,
+		client:      &http.Client{Timeout: timeout, Transport: transport},
+		baseDelay:   200 * time.Millisecond,
+		maxDelay:    5 * time.Second,
+	}
+}
+
+// StatusError is returned when the upstream API answers with a non-OK status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
+}
+
+// Get fetches url, retrying transient failures. On success the caller owns
+// the response body. Any status other than 200 OK is returned as a
+// *StatusError once retries are exhausted.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	attempts := max(c.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.client.Do(req)
+		var retryAfter time.Duration
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			log.Printf("[DEBUG] GET %s attempt %d/%d failed: %v", url, attempt, attempts, err)
+		case resp.StatusCode == http.StatusOK:
+			log.Printf("[DEBUG] GET %s succeeded after %d attempt(s)", url, attempt)
+			return resp, nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			err = &StatusError{StatusCode: resp.StatusCode}
+			drainAndClose(resp)
+			log.Printf("[DEBUG] GET %s attempt %d/%d failed: %v", url, attempt, attempts, err)
+		default:
+			drainAndClose(resp)
+			log.Printf("[ERROR] GET %s returned non-retryable status %d after %d attempt(s)", url, resp.StatusCode, attempt)
+			return nil, &StatusError{StatusCode: resp.StatusCode}
+		}
+
+		if attempt >= attempts {
+			log.Printf("[ERROR] GET %s giving up after %d attempt(s)", url, attempt)
+			return nil, err
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > 0 {
+			// Never retry sooner than the server asked; if it asks for
+			// longer than we are willing to wait, give up now.
+			if retryAfter > c.maxDelay {
+				log.Printf("[ERROR] GET %s Retry-After %s exceeds max delay %s, giving up after %d attempt(s)", url, retryAfter, c.maxDelay, attempt)
+				return nil, err
+			}
+			delay = retryAfter
+		}
+		log.Printf("[DEBUG] Retrying GET %s in %s", url, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// GetJSON fetches url with Get and decodes the JSON body into v.
+func (c *Client) GetJSON(ctx context.Context, url string, v any) error {
+	resp, err := c.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return nil
+}
+
+// backoff returns a random delay in [0, min(maxDelay, baseDelay*2^(attempt-1))]
+// ("full jitter"), so concurrent callers don't retry in lockstep.
+func (c *Client) backoff(attempt int) time.Duration {
+	ceiling := c.baseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > c.maxDelay {
+		ceiling = c.maxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// parseRetryAfter understands both forms of the Retry-After header:
+// delay-seconds and an HTTP date. It returns 0 if the header is absent
+// or unparseable.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// drainAndClose reads what is left of the body so the connection can be
+// reused, then closes it.
+func drainAndClose(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
@@ -0,0 +1,27 @@
+// Package timezone resolves the IANA timezone names the MCP servers accept
+// as tool input.
+package timezone
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	// Embed the IANA database so timezones resolve in minimal images
+	// without tzdata installed.
+	_ "time/tzdata"
+)
+
+// Load resolves an IANA timezone name. An empty name means UTC.
+func Load(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	// "Local" would make answers depend on the server's zone
+	if err != nil || name == "Local" {
+		log.Printf("[ERROR] Unknown timezone %q: %v", name, err)
+		return nil, fmt.Errorf("unknown timezone %q, use an IANA name such as Europe/Berlin", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Tool input/output types
+
+type GetAirQualityInput struct {
+	Latitude  *float64 `json:"latitude,omitempty" jsonschema:"latitude coordinate (-90 to 90); omit to use location"`
+	Longitude *float64 `json:"longitude,omitempty" jsonschema:"longitude coordinate (-180 to 180); omit to use location"`
+	Location  string   `json:"location,omitempty" jsonschema:"place name used when coordinates are omitted, optionally qualified, e.g. Springfield, Illinois"`
+}
+
+type AirQualityOutput struct {
+	Latitude            float64      `json:"latitude"`
+	Longitude           float64      `json:"longitude"`
+	Time                string       `json:"time"`
+	PM25                float64      `json:"pm2_5"`
+	PM10                float64      `json:"pm10"`
+	Ozone               float64      `json:"ozone"`
+	NitrogenDioxide     float64      `json:"nitrogen_dioxide"`
+	ConcentrationUnit   string       `json:"concentration_unit"`
+	EuropeanAQI         int          `json:"european_aqi"`
+	EuropeanAQICategory string       `json:"european_aqi_category"`
+	USAQI               int          `json:"us_aqi"`
+	USAQICategory       string       `json:"us_aqi_category"`
+	Location            *GeoLocation `json:"location,omitempty"`
+}
+
+type GetUVIndexInput struct {
+	Latitude  *float64 `json:"latitude,omitempty" jsonschema:"latitude coordinate (-90 to 90); omit to use location"`
+	Longitude *float64 `json:"longitude,omitempty" jsonschema:"longitude coordinate (-180 to 180); omit to use location"`
+	Location  string   `json:"location,omitempty" jsonschema:"place name used when coordinates are omitted, optionally qualified, e.g. Springfield, Illinois"`
+}
+
+type UVIndexOutput struct {
+	Latitude        float64      `json:"latitude"`
+	Longitude       float64      `json:"longitude"`
+	Time            string       `json:"time"`
+	UVIndex         float64      `json:"uv_index"`
+	UVIndexClearSky float64      `json:"uv_index_clear_sky"`
+	Category        string       `json:"category"`
+	Location        *GeoLocation `json:"location,omitempty"`
+}
+
+// Index bands. Each band covers values up to and including Max; the last
+// band is open-ended.
+type indexBand struct {
+	Max   float64
+	Label string
+}
+
+// European AQI bands as defined by the European Environment Agency
+var europeanAQIBands = []indexBand{
+	{20, "Good"},
+	{40, "Fair"},
+	{60, "Moderate"},
+	{80, "Poor"},
+	{100, "Very poor"},
+	{math.Inf(1), "Extremely poor"},
+}
+
+// US AQI bands as defined by the EPA
+var usAQIBands = []indexBand{
+	{50, "Good"},
+	{100, "Moderate"},
+	{150, "Unhealthy for sensitive groups"},
+	{200, "Unhealthy"},
+	{300, "Very unhealthy"},
+	{math.Inf(1), "Hazardous"},
+}
+
+// UV index bands as defined by the WHO
+var uvIndexBands = []indexBand{
+	{2, "Low"},
+	{5, "Moderate"},
+	{7, "High"},
+	{10, "Very high"},
+	{math.Inf(1), "Extreme"},
+}
+
+func getIndexCategory(bands []indexBand, value float64) string {
+	if value < 0 || math.IsNaN(value) {
+		return "Unknown"
+	}
+	for _, b := range bands {
+		if value <= b.Max {
+			return b.Label
+		}
+	}
+	return "Unknown"
+}
+
+// Open-Meteo air quality API response structure
+type OpenMeteoAirQualityResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Current   struct {
+		Time            string  `json:"time"`
+		PM10            float64 `json:"pm10"`
+		PM25            float64 `json:"pm2_5"`
+		Ozone           float64 `json:"ozone"`
+		NitrogenDioxide float64 `json:"nitrogen_dioxide"`
+		EuropeanAQI     float64 `json:"european_aqi"`
+		USAQI           float64 `json:"us_aqi"`
+		UVIndex         float64 `json:"uv_index"`
+		UVIndexClearSky float64 `json:"uv_index_clear_sky"`
+	} `json:"current"`
+}
+
+// fetchAirQuality returns current air quality from weatherBackend through
+// the response cache. It shares the current conditions TTL.
+func fetchAirQuality(ctx context.Context, lat, lon float64) (OpenMeteoAirQualityResponse, string, error) {
+	fetch := func() (OpenMeteoAirQualityResponse, error) {
+		return weatherBackend.AirQuality(ctx, lat, lon)
+	}
+	if responseCache == nil {
+		return cachedFetch(nil, "", 0, fetch)
+	}
+	key := responseCache.key("airquality", lat, lon, 0)
+	return cachedFetch(responseCache, key, responseCache.currentTTL, fetch)
+}
+
+// Tool handlers
+
+func getAirQuality(ctx context.Context, _ *mcp.CallToolRequest, input GetAirQualityInput) (*mcp.CallToolResult, AirQualityOutput, error) {
+	log.Printf("[DEBUG] get_air_quality tool called with input: latitude=%s, longitude=%s, location=%s",
+		formatCoordinate(input.Latitude), formatCoordinate(input.Longitude), input.Location)
+
+	lat, lon, place, err := resolveLocation(ctx, input.Location, input.Latitude, input.Longitude)
+	if err != nil {
+		return nil, AirQualityOutput{}, err
+	}
+
+	apiResp, cacheStatus, err := fetchAirQuality(ctx, lat, lon)
+	if err != nil {
+		log.Printf("[ERROR] Failed to fetch air quality data: %v", err)
+		return nil, AirQualityOutput{}, fmt.Errorf("failed to fetch air quality data: %w", err)
+	}
+
+	c := apiResp.Current
+	result := AirQualityOutput{
+		Latitude:            apiResp.Latitude,
+		Longitude:           apiResp.Longitude,
+		Time:                c.Time,
+		PM25:                c.PM25,
+		PM10:                c.PM10,
+		Ozone:               c.Ozone,
+		NitrogenDioxide:     c.NitrogenDioxide,
+		ConcentrationUnit:   "μg/m³",
+		EuropeanAQI:         int(math.Round(c.EuropeanAQI)),
+		EuropeanAQICategory: getIndexCategory(europeanAQIBands, c.EuropeanAQI),
+		USAQI:               int(math.Round(c.USAQI)),
+		USAQICategory:       getIndexCategory(usAQIBands, c.USAQI),
+		Location:            place,
+	}
+	log.Printf("[DEBUG] Air quality retrieved: european_aqi=%d (%s), us_aqi=%d (%s), cache=%s",
+		result.EuropeanAQI, result.EuropeanAQICategory, result.USAQI, result.USAQICategory, cacheStatus)
+
+	return cacheResult(cacheStatus), result, nil
+}
+
+func getUVIndex(ctx context.Context, _ *mcp.CallToolRequest, input GetUVIndexInput) (*mcp.CallToolResult, UVIndexOutput, error) {
+	log.Printf("[DEBUG] get_uv_index tool called with input: latitude=%s, longitude=%s, location=%s",
+		formatCoordinate(input.Latitude), formatCoordinate(input.Longitude), input.Location)
+
+	lat, lon, place, err := resolveLocation(ctx, input.Location, input.Latitude, input.Longitude)
+	if err != nil {
+		return nil, UVIndexOutput{}, err
+	}
+
+	apiResp, cacheStatus, err := fetchAirQuality(ctx, lat, lon)
+	if err != nil {
+		log.Printf("[ERROR] Failed to fetch UV index data: %v", err)
+		return nil, UVIndexOutput{}, fmt.Errorf("failed to fetch UV index data: %w", err)
+	}
+
+	c := apiResp.Current
+	result := UVIndexOutput{
+		Latitude:        apiResp.Latitude,
+		Longitude:       apiResp.Longitude,
+		Time:            c.Time,
+		UVIndex:         c.UVIndex,
+		UVIndexClearSky: c.UVIndexClearSky,
+		Category:        getIndexCategory(uvIndexBands, c.UVIndex),
+		Location:        place,
+	}
+	log.Printf("[DEBUG] UV index retrieved: uv_index=%.1f (%s), cache=%s", result.UVIndex, result.Category, cacheStatus)
+
+	return cacheResult(cacheStatus), result, nil
+}
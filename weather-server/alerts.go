@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// Tool input/output types
+
+type GetWeatherAlertsInput struct {
+	Latitude  *float64 `json:"latitude,omitempty" jsonschema:"latitude coordinate (-90 to 90); omit to use location"`
+	Longitude *float64 `json:"longitude,omitempty" jsonschema:"longitude coordinate (-180 to 180); omit to use location"`
+	Location  string   `json:"location,omitempty" jsonschema:"place name used when coordinates are omitted, optionally qualified, e.g. Springfield, Illinois"`
+	Days      int      `json:"days,omitempty" jsonschema:"number of forecast days to check (1-7, default 7)"`
+}
+
+type AlertValue struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+}
+
+type WeatherAlert struct {
+	Rule     string       `json:"rule"`
+	Severity string       `json:"severity"`
+	Message  string       `json:"message"`
+	Metric   string       `json:"metric"`
+	Start    string       `json:"start"`
+	End      string       `json:"end"`
+	Values   []AlertValue `json:"values"`
+}
+
+type WeatherAlertsOutput struct {
+	Latitude       float64        `json:"latitude"`
+	Longitude      float64        `json:"longitude"`
+	Days           int            `json:"days"`
+	RulesEvaluated int            `json:"rules_evaluated"`
+	Alerts         []WeatherAlert `json:"alerts"`
+	Units          Units          `json:"units"`
+	Location       *GeoLocation   `json:"location,omitempty"`
+}
+
+// AlertRule describes a condition on one daily forecast metric. Rules are
+// evaluated in metric units (°C, km/h, mm).
+type AlertRule struct {
+	Name      string  `json:"name" yaml:"name"`
+	Metric    string  `json:"metric" yaml:"metric"`
+	Operator  string  `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Threshold float64 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Codes     []int   `json:"codes,omitempty" yaml:"codes,omitempty"`
+	Severity  string  `json:"severity" yaml:"severity"`
+	Message   string  `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// alertRulesFile is the layout of a rules file.
+type alertRulesFile struct {
+	Rules []AlertRule `json:"rules" yaml:"rules"`
+}
+
+// Metrics an alert rule can test, mapped to the daily forecast value.
+var alertMetrics = map[string]func(d OpenMeteoForecastResponse, i int) (float64, bool){
+	"temp_max":      func(d OpenMeteoForecastResponse, i int) (float64, bool) { return at(d.Daily.Temperature2mMax, i) },
+	"temp_min":      func(d OpenMeteoForecastResponse, i int) (float64, bool) { return at(d.Daily.Temperature2mMin, i) },
+	"precipitation": func(d OpenMeteoForecastResponse, i int) (float64, bool) { return at(d.Daily.PrecipitationSum, i) },
+	"wind_gusts":    func(d OpenMeteoForecastResponse, i int) (float64, bool) { return at(d.Daily.WindGusts10mMax, i) },
+	"weather_code": func(d OpenMeteoForecastResponse, i int) (float64, bool) {
+		code, ok := at(d.Daily.WeatherCode, i)
+		return float64(code), ok
+	},
+}
+
+var alertOperators = map[string]func(v, threshold float64) bool{
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+}
+
+var alertSeverities = []string{"minor", "moderate", "severe", "extreme"}
+
+// defaultAlertRules are used when no rules file is configured.
+var defaultAlertRules = []AlertRule{
+	{Name: "heavy_rain", Metric: "precipitation", Operator: ">", Threshold: 20, Severity: "moderate", Message: "Heavy rain expected"},
+	{Name: "extreme_heat", Metric: "temp_max", Operator: ">", Threshold: 35, Severity: "severe", Message: "Extreme heat expected"},
+	{Name: "hard_frost", Metric: "temp_min", Operator: "<", Threshold: -10, Severity: "moderate", Message: "Hard frost expected"},
+	{Name: "thunderstorm", Metric: "weather_code", Codes: []int{95, 96, 99}, Severity: "severe", Message: "Thunderstorms expected"},
+	{Name: "strong_wind_gusts", Metric: "wind_gusts", Operator: ">", Threshold: 75, Severity: "moderate", Message: "Strong wind gusts expected"},
+}
+
+// alertRules is configured in main from the -alert-rules flag.
+var alertRules = defaultAlertRules
+
+// loadAlertRules reads rules from a YAML (.yaml, .yml) or JSON file and
+// validates them.
+func loadAlertRules(path string) ([]AlertRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file alertRulesFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("%s defines no rules", filepath.Base(path))
+	}
+	for i, r := range file.Rules {
+		if err := validateAlertRule(r); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i+1, r.Name, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func validateAlertRule(r AlertRule) error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, ok := alertMetrics[r.Metric]; !ok {
+		return fmt.Errorf("unknown metric %q (must be temp_max, temp_min, precipitation, wind_gusts, or weather_code)", r.Metric)
+	}
+	if !slices.Contains(alertSeverities, r.Severity) {
+		return fmt.Errorf("unknown severity %q (must be one of %s)", r.Severity, strings.Join(alertSeverities, ", "))
+	}
+	if len(r.Codes) > 0 {
+		if r.Operator != "" {
+			return fmt.Errorf("use either codes or operator, not both")
+		}
+		return nil
+	}
+	if _, ok := alertOperators[r.Operator]; !ok {
+		return fmt.Errorf("unknown operator %q (must be >, >=, <, or <=), or list codes", r.Operator)
+	}
+	return nil
+}
+
+// matches reports whether the rule fires for value.
+func (r AlertRule) matches(value float64) bool {
+	if len(r.Codes) > 0 {
+		return slices.Contains(r.Codes, int(value))
+	}
+	return alertOperators[r.Operator](value, r.Threshold)
+}
+
+// evaluateAlerts runs each rule over the daily forecast. Consecutive days
+// that trigger the same rule are merged into one alert.
+func evaluateAlerts(rules []AlertRule, forecast OpenMeteoForecastResponse) []WeatherAlert {
+	alerts := []WeatherAlert{}
+	for _, rule := range rules {
+		metric := alertMetrics[rule.Metric]
+		current := -1 // index in alerts of the run being extended
+		for i, date := range forecast.Daily.Time {
+			value, ok := metric(forecast, i)
+			if !ok || !rule.matches(value) {
+				current = -1
+				continue
+			}
+			if current < 0 {
+				alerts = append(alerts, WeatherAlert{
+					Rule:     rule.Name,
+					Severity: rule.Severity,
+					Message:  firstNonEmpty(rule.Message, rule.Name),
+					Metric:   rule.Metric,
+					Start:    date,
+				})
+				current = len(alerts) - 1
+			}
+			alerts[current].End = date
+			alerts[current].Values = append(alerts[current].Values, AlertValue{Date: date, Value: value})
+		}
+	}
+
+	// Most severe first, then chronologically
+	slices.SortStableFunc(alerts, func(a, b WeatherAlert) int {
+		if d := slices.Index(alertSeverities, b.Severity) - slices.Index(alertSeverities, a.Severity); d != 0 {
+			return d
+		}
+		return strings.Compare(a.Start, b.Start)
+	})
+	return alerts
+}
+
+func at[T any](s []T, i int) (T, bool) {
+	if i < len(s) {
+		return s[i], true
+	}
+	var zero T
+	return zero, false
+}
+
+// Tool handler
+
+func getWeatherAlerts(ctx context.Context, _ *mcp.CallToolRequest, input GetWeatherAlertsInput) (*mcp.CallToolResult, WeatherAlertsOutput, error) {
+	log.Printf("[DEBUG] get_weather_alerts tool called with input: latitude=%s, longitude=%s, location=%s, days=%d",
+		formatCoordinate(input.Latitude), formatCoordinate(input.Longitude), input.Location, input.Days)
+
+	lat, lon, place, err := resolveLocation(ctx, input.Location, input.Latitude, input.Longitude)
+	if err != nil {
+		return nil, WeatherAlertsOutput{}, err
+	}
+
+	// Validate and set default days
+	days := input.Days
+	if days <= 0 {
+		days = 7
+		log.Printf("[DEBUG] Days was <= 0, using default: %d", days)
+	}
+	if days > 7 {
+		days = 7
+		log.Printf("[DEBUG] Days exceeded max, capping at: %d", days)
+	}
+
+	apiResp, cacheStatus, err := fetchForecast(ctx, lat, lon, days)
+	if err != nil {
+		log.Printf("[ERROR] Failed to fetch forecast data: %v", err)
+		return nil, WeatherAlertsOutput{}, fmt.Errorf("failed to fetch forecast data: %w", err)
+	}
+
+	alerts := evaluateAlerts(alertRules, apiResp)
+	log.Printf("[DEBUG] Weather alerts evaluated: %d rules, %d alerts, cache=%s", len(alertRules), len(alerts), cacheStatus)
+
+	return cacheResult(cacheStatus), WeatherAlertsOutput{
+		Latitude:       apiResp.Latitude,
+		Longitude:      apiResp.Longitude,
+		Days:           days,
+		RulesEvaluated: len(alertRules),
+		Alerts:         alerts,
+		Units:          unitSystems["metric"].Units,
+		Location:       place,
+	}, nil
+}
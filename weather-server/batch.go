@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/coords"
+)
+
+// Tool input/output types
+
+type BatchLocation struct {
+	Latitude  float64 `json:"latitude" jsonschema:"latitude coordinate (-90 to 90)"`
+	Longitude float64 `json:"longitude" jsonschema:"longitude coordinate (-180 to 180)"`
+	Label     string  `json:"label,omitempty" jsonschema:"optional name echoed back in the result, e.g. a city name"`
+}
+
+type GetWeatherBatchInput struct {
+	Locations []BatchLocation `json:"locations" jsonschema:"locations to look up"`
+	Units     string          `json:"units,omitempty" jsonschema:"unit system for the output: metric (default), imperial, or si"`
+}
+
+type BatchWeatherResult struct {
+	Index     int                   `json:"index"`
+	Label     string                `json:"label,omitempty"`
+	Latitude  float64               `json:"latitude"`
+	Longitude float64               `json:"longitude"`
+	Weather   *CurrentWeatherOutput `json:"weather,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	Cache     string                `json:"cache,omitempty"`
+}
+
+type WeatherBatchOutput struct {
+	Results   []BatchWeatherResult `json:"results"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+}
+
+// Batch limits, set in main from the -batch-max-locations and
+// -batch-workers flags.
+var (
+	batchMaxLocations = 10
+	batchWorkers      = 4
+)
+
+// Tool handler
+
+func getWeatherBatch(ctx context.Context, _ *mcp.CallToolRequest, input GetWeatherBatchInput) (*mcp.CallToolResult, WeatherBatchOutput, error) {
+	log.Printf("[DEBUG] get_weather_batch tool called with input: locations=%d, units=%s", len(input.Locations), input.Units)
+
+	if len(input.Locations) == 0 {
+		log.Printf("[ERROR] Locations is required but was empty")
+		return nil, WeatherBatchOutput{}, fmt.Errorf("at least one location is required")
+	}
+	if len(input.Locations) > batchMaxLocations {
+		log.Printf("[ERROR] Too many locations: %d (max %d)", len(input.Locations), batchMaxLocations)
+		return nil, WeatherBatchOutput{}, fmt.Errorf("at most %d locations are allowed per batch, got %d", batchMaxLocations, len(input.Locations))
+	}
+	units, err := parseUnits(input.Units)
+	if err != nil {
+		return nil, WeatherBatchOutput{}, err
+	}
+
+	// Each worker writes only to its own results[i], so no locking is needed
+	results := make([]BatchWeatherResult, len(input.Locations))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(batchWorkers, len(input.Locations)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = lookupBatchLocation(ctx, i, input.Locations[i], units)
+			}
+		}()
+	}
+	for i := range input.Locations {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	output := WeatherBatchOutput{Results: results}
+	for _, r := range results {
+		if r.Error != "" {
+			output.Failed++
+		} else {
+			output.Succeeded++
+		}
+	}
+	log.Printf("[DEBUG] Batch completed: %d succeeded, %d failed", output.Succeeded, output.Failed)
+
+	return nil, output, nil
+}
+
+// lookupBatchLocation fetches current weather for one batch entry, turning
+// any error into the result's Error field.
+func lookupBatchLocation(ctx context.Context, index int, loc BatchLocation, units unitSystem) BatchWeatherResult {
+	result := BatchWeatherResult{
+		Index:     index,
+		Label:     loc.Label,
+		Latitude:  loc.Latitude,
+		Longitude: loc.Longitude,
+	}
+
+	if err := coords.Validate(loc.Latitude, loc.Longitude); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	weather, cacheStatus, err := currentWeather(ctx, loc.Latitude, loc.Longitude, units)
+	result.Cache = cacheStatus
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Weather = &weather
+	return result
+}
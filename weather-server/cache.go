@@ -0,0 +1,178 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ttlCache is a size-bounded LRU cache whose entries also expire after a
+// per-entry TTL. It is safe for concurrent use.
+type ttlCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List // front is most recently used
+	items    map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+func newTTLCache(capacity int) *ttlCache {
+	return &ttlCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key if it exists and has not expired.
+func (c *ttlCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set stores value under key for ttl, evicting the least recently used
+// entry if the cache is full.
+func (c *ttlCache) Set(key string, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value, entry.expires = value, expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// weatherCache caches upstream weather responses keyed on coordinates
+// rounded to a fixed number of decimal places. Forecasts change less often
+// than current conditions, so each kind of response has its own TTL.
+type weatherCache struct {
+	cache       *ttlCache
+	precision   int
+	currentTTL  time.Duration
+	forecastTTL time.Duration
+}
+
+// responseCache is configured in main; nil means caching is disabled.
+var responseCache *weatherCache
+
+func newWeatherCache(size, precision int, currentTTL, forecastTTL time.Duration) *weatherCache {
+	return &weatherCache{
+		cache:       newTTLCache(size),
+		precision:   precision,
+		currentTTL:  currentTTL,
+		forecastTTL: forecastTTL,
+	}
+}
+
+// key builds the cache key for a kind of request at the given coordinates.
+// n is the requested number of days or hours, or 0 if the request has none.
+func (c *weatherCache) key(kind string, lat, lon float64, n int) string {
+	return fmt.Sprintf("%s:%.*f:%.*f:%d", kind, c.precision, lat, c.precision, lon, n)
+}
+
+// Cache status values reported in tool result metadata.
+const (
+	cacheHit      = "hit"
+	cacheMiss     = "miss"
+	cacheDisabled = "disabled"
+)
+
+// cachedFetch returns the cached value for key, or calls fetch and caches
+// its result for ttl. Errors are never cached. The returned status is one
+// of cacheHit, cacheMiss or cacheDisabled.
+func cachedFetch[T any](c *weatherCache, key string, ttl time.Duration, fetch func() (T, error)) (T, string, error) {
+	if c == nil {
+		v, err := fetch()
+		return v, cacheDisabled, err
+	}
+
+	if v, ok := c.cache.Get(key); ok {
+		log.Printf("[DEBUG] Cache hit: %s", key)
+		return v.(T), cacheHit, nil
+	}
+
+	log.Printf("[DEBUG] Cache miss: %s", key)
+	v, err := fetch()
+	if err != nil {
+		return v, cacheMiss, err
+	}
+	c.cache.Set(key, v, ttl)
+	return v, cacheMiss, nil
+}
+
+// fetchCurrent returns current conditions from weatherBackend through the
+// response cache.
+func fetchCurrent(ctx context.Context, lat, lon float64) (OpenMeteoCurrentResponse, string, error) {
+	fetch := func() (OpenMeteoCurrentResponse, error) {
+		return weatherBackend.Current(ctx, lat, lon)
+	}
+	if responseCache == nil {
+		return cachedFetch(nil, "", 0, fetch)
+	}
+	key := responseCache.key("current", lat, lon, 0)
+	return cachedFetch(responseCache, key, responseCache.currentTTL, fetch)
+}
+
+// fetchForecast returns a daily forecast from weatherBackend through the
+// response cache.
+func fetchForecast(ctx context.Context, lat, lon float64, days int) (OpenMeteoForecastResponse, string, error) {
+	fetch := func() (OpenMeteoForecastResponse, error) {
+		return weatherBackend.Forecast(ctx, lat, lon, days)
+	}
+	if responseCache == nil {
+		return cachedFetch(nil, "", 0, fetch)
+	}
+	key := responseCache.key("forecast", lat, lon, days)
+	return cachedFetch(responseCache, key, responseCache.forecastTTL, fetch)
+}
+
+// fetchHourly returns an hourly forecast from weatherBackend through the
+// response cache. Hourly forecasts share the forecast TTL.
+func fetchHourly(ctx context.Context, lat, lon float64, hours int) (OpenMeteoHourlyResponse, string, error) {
+	fetch := func() (OpenMeteoHourlyResponse, error) {
+		return weatherBackend.Hourly(ctx, lat, lon, hours)
+	}
+	if responseCache == nil {
+		return cachedFetch(nil, "", 0, fetch)
+	}
+	key := responseCache.key("hourly", lat, lon, hours)
+	return cachedFetch(responseCache, key, responseCache.forecastTTL, fetch)
+}
+
+// cacheResult returns a tool result whose metadata reports the cache status.
+func cacheResult(status string) *mcp.CallToolResult {
+	return &mcp.CallToolResult{Meta: mcp.Meta{"cache": status}}
+}
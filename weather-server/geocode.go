@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/coords"
+)
+
+// Tool input/output types
+
+type GeocodeLocationInput struct {
+	Name        string `json:"name" jsonschema:"place name to search for, e.g. Berlin or Springfield"`
+	CountryCode string `json:"country_code,omitempty" jsonschema:"ISO 3166-1 alpha-2 country code to restrict results, e.g. US"`
+	Count       int    `json:"count,omitempty" jsonschema:"maximum number of results (1-10, default 5)"`
+}
+
+type GeoLocation struct {
+	Name        string  `json:"name"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Elevation   float64 `json:"elevation_m"`
+	Country     string  `json:"country"`
+	CountryCode string  `json:"country_code"`
+	Region      string  `json:"region"`
+	Timezone    string  `json:"timezone"`
+	Population  int     `json:"population"`
+}
+
+type GeocodeLocationOutput struct {
+	Query   string        `json:"query"`
+	Results []GeoLocation `json:"results"`
+}
+
+// Open-Meteo geocoding API response structure
+type OpenMeteoGeocodingResponse struct {
+	Results []struct {
+		Name        string  `json:"name"`
+		Latitude    float64 `json:"latitude"`
+		Longitude   float64 `json:"longitude"`
+		Elevation   float64 `json:"elevation"`
+		Country     string  `json:"country"`
+		CountryCode string  `json:"country_code"`
+		Admin1      string  `json:"admin1"`
+		Timezone    string  `json:"timezone"`
+		Population  int     `json:"population"`
+	} `json:"results"`
+}
+
+func (r OpenMeteoGeocodingResponse) locations() []GeoLocation {
+	locations := make([]GeoLocation, 0, len(r.Results))
+	for _, res := range r.Results {
+		locations = append(locations, GeoLocation{
+			Name:        res.Name,
+			Latitude:    res.Latitude,
+			Longitude:   res.Longitude,
+			Elevation:   res.Elevation,
+			Country:     res.Country,
+			CountryCode: res.CountryCode,
+			Region:      res.Admin1,
+			Timezone:    res.Timezone,
+			Population:  res.Population,
+		})
+	}
+	return locations
+}
+
+// String formats a location for humans, e.g. "Springfield, Illinois, United States (39.80, -89.64)".
+func (l GeoLocation) String() string {
+	parts := []string{l.Name}
+	if l.Region != "" && l.Region != l.Name {
+		parts = append(parts, l.Region)
+	}
+	if l.Country != "" {
+		parts = append(parts, l.Country)
+	}
+	return fmt.Sprintf("%s (%.2f, %.2f)", strings.Join(parts, ", "), l.Latitude, l.Longitude)
+}
+
+// fetchGeocode looks up a place name through the response cache. Place
+// names rarely move, so results share the forecast TTL.
+func fetchGeocode(ctx context.Context, name, countryCode string, count int) ([]GeoLocation, string, error) {
+	fetch := func() ([]GeoLocation, error) {
+		resp, err := weatherBackend.Geocode(ctx, name, countryCode, count)
+		if err != nil {
+			return nil, err
+		}
+		return resp.locations(), nil
+	}
+	if responseCache == nil {
+		return cachedFetch(nil, "", 0, fetch)
+	}
+	key := fmt.Sprintf("geocode:%s:%s:%d", strings.ToLower(name), strings.ToUpper(countryCode), count)
+	return cachedFetch(responseCache, key, responseCache.forecastTTL, fetch)
+}
+
+// Tool handler
+
+func geocodeLocation(ctx context.Context, _ *mcp.CallToolRequest, input GeocodeLocationInput) (*mcp.CallToolResult, GeocodeLocationOutput, error) {
+	log.Printf("[DEBUG] geocode_location tool called with input: name=%s, country_code=%s, count=%d",
+		input.Name, input.CountryCode, input.Count)
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		log.Printf("[ERROR] Name is required but was empty")
+		return nil, GeocodeLocationOutput{}, fmt.Errorf("name is required")
+	}
+
+	count := input.Count
+	if count <= 0 {
+		count = 5
+		log.Printf("[DEBUG] Count was <= 0, using default: %d", count)
+	}
+	if count > 10 {
+		count = 10
+		log.Printf("[DEBUG] Count exceeded max, capping at: %d", count)
+	}
+
+	locations, cacheStatus, err := fetchGeocode(ctx, name, input.CountryCode, count)
+	if err != nil {
+		log.Printf("[ERROR] Failed to geocode location: %v", err)
+		return nil, GeocodeLocationOutput{}, fmt.Errorf("failed to geocode location: %w", err)
+	}
+
+	log.Printf("[DEBUG] Geocoding completed: found %d results, cache=%s", len(locations), cacheStatus)
+	return cacheResult(cacheStatus), GeocodeLocationOutput{
+		Query:   name,
+		Results: locations,
+	}, nil
+}
+
+// Location resolution for the weather tools
+
+// candidateCount is how many matches are requested when resolving a
+// location string for a weather tool.
+const candidateCount = 10
+
+// resolveLocation turns the coordinate and location inputs of a weather tool
+// into validated coordinates. Explicit coordinates win; otherwise location is
+// geocoded. The returned GeoLocation is nil when coordinates were given.
+//
+// A location may carry a qualifier after a comma ("Springfield, Illinois" or
+// "Paris, FR") that must match the region, country or country code. If
+// several places still match and none clearly dominates by population, the
+// error lists the candidates so the caller can retry with a qualifier or
+// with coordinates.
+func resolveLocation(ctx context.Context, location string, lat, lon *float64) (float64, float64, *GeoLocation, error) {
+	switch {
+	case lat != nil && lon != nil:
+		if err := coords.Validate(*lat, *lon); err != nil {
+			return 0, 0, nil, err
+		}
+		return *lat, *lon, nil, nil
+	case lat != nil || lon != nil:
+		log.Printf("[ERROR] Only one of latitude/longitude was provided")
+		return 0, 0, nil, fmt.Errorf("latitude and longitude must be provided together")
+	case strings.TrimSpace(location) == "":
+		log.Printf("[ERROR] Neither coordinates nor location were provided")
+		return 0, 0, nil, fmt.Errorf("either latitude and longitude or location is required")
+	}
+
+	name, qualifier, _ := strings.Cut(location, ",")
+	name, qualifier = strings.TrimSpace(name), strings.TrimSpace(qualifier)
+	log.Printf("[DEBUG] Resolving location: name=%s, qualifier=%s", name, qualifier)
+
+	locations, _, err := fetchGeocode(ctx, name, "", candidateCount)
+	if err != nil {
+		log.Printf("[ERROR] Failed to geocode location: %v", err)
+		return 0, 0, nil, fmt.Errorf("failed to geocode location: %w", err)
+	}
+
+	var candidates []GeoLocation
+	for _, l := range locations {
+		if qualifier == "" || matchesQualifier(l, qualifier) {
+			candidates = append(candidates, l)
+		}
+	}
+
+	if len(candidates) == 0 {
+		log.Printf("[ERROR] No locations found matching %q", location)
+		return 0, 0, nil, fmt.Errorf("no locations found matching %q", location)
+	}
+
+	// Geocoding results come ordered by relevance. Take the first one unless
+	// the runner-up is a comparably sized place.
+	if len(candidates) > 1 && candidates[1].Population*10 >= candidates[0].Population {
+		shown := candidates[:min(len(candidates), 5)]
+		lines := make([]string, 0, len(shown))
+		for _, c := range shown {
+			lines = append(lines, "- "+c.String())
+		}
+		log.Printf("[ERROR] Location %q is ambiguous: %d candidates", location, len(candidates))
+		return 0, 0, nil, fmt.Errorf("location %q is ambiguous, candidates:\n%s\nadd a region or country (e.g. %q) or pass latitude and longitude",
+			location, strings.Join(lines, "\n"), shown[0].Name+", "+firstNonEmpty(shown[0].Region, shown[0].Country))
+	}
+
+	place := candidates[0]
+	log.Printf("[DEBUG] Resolved location %q to %s", location, place)
+	return place.Latitude, place.Longitude, &place, nil
+}
+
+// matchesQualifier reports whether a location's region, country or country
+// code matches the qualifier (case-insensitive, prefix match for names).
+func matchesQualifier(l GeoLocation, qualifier string) bool {
+	q := strings.ToLower(qualifier)
+	return strings.EqualFold(l.CountryCode, qualifier) ||
+		(l.Region != "" && strings.HasPrefix(strings.ToLower(l.Region), q)) ||
+		(l.Country != "" && strings.HasPrefix(strings.ToLower(l.Country), q))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// formatCoordinate formats an optional coordinate for logging.
+func formatCoordinate(v *float64) string {
+	if v == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%.4f", *v)
+}
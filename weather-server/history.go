@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/coords"
+)
+
+// Tool input/output types
+
+type GetHistoricalWeatherInput struct {
+	Latitude  float64 `json:"latitude" jsonschema:"latitude coordinate (-90 to 90)"`
+	Longitude float64 `json:"longitude" jsonschema:"longitude coordinate (-180 to 180)"`
+	StartDate string  `json:"start_date" jsonschema:"first day in YYYY-MM-DD format"`
+	EndDate   string  `json:"end_date,omitempty" jsonschema:"last day in YYYY-MM-DD format, defaults to start_date"`
+	Units     string  `json:"units,omitempty" jsonschema:"unit system for the output: metric (default), imperial, or si"`
+}
+
+type HistoricalWeatherOutput struct {
+	Latitude  float64         `json:"latitude"`
+	Longitude float64         `json:"longitude"`
+	StartDate string          `json:"start_date"`
+	EndDate   string          `json:"end_date"`
+	Daily     []DailyForecast `json:"daily"`
+	Units     Units           `json:"units"`
+}
+
+// historyMaxDays is the longest date range get_historical_weather accepts.
+// main sets it from the -history-max-days flag.
+var historyMaxDays = 31
+
+// archiveStart is the first day covered by the Open-Meteo archive.
+var archiveStart = time.Date(1940, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// parseDateRange validates a start/end date pair for a historical lookup.
+// An empty end defaults to start. The range must lie between archiveStart
+// and yesterday (UTC) and span at most historyMaxDays days.
+func parseDateRange(start, end string) (time.Time, time.Time, error) {
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		log.Printf("[ERROR] Invalid start_date format: %v", err)
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start_date format, use YYYY-MM-DD: %w", err)
+	}
+	endDate := startDate
+	if end != "" {
+		endDate, err = time.Parse("2006-01-02", end)
+		if err != nil {
+			log.Printf("[ERROR] Invalid end_date format: %v", err)
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end_date format, use YYYY-MM-DD: %w", err)
+		}
+	}
+
+	if endDate.Before(startDate) {
+		log.Printf("[ERROR] end_date %s is before start_date %s", end, start)
+		return time.Time{}, time.Time{}, fmt.Errorf("end_date must not be before start_date")
+	}
+	if startDate.Before(archiveStart) {
+		log.Printf("[ERROR] start_date %s is before archive start", start)
+		return time.Time{}, time.Time{}, fmt.Errorf("start_date must be on or after %s", archiveStart.Format("2006-01-02"))
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !endDate.Before(today) {
+		log.Printf("[ERROR] end_date %s is not in the past", endDate.Format("2006-01-02"))
+		return time.Time{}, time.Time{}, fmt.Errorf("end_date must be before today (%s); use get_forecast for today and later", today.Format("2006-01-02"))
+	}
+	if span := int(endDate.Sub(startDate).Hours()/24) + 1; span > historyMaxDays {
+		log.Printf("[ERROR] Date range spans %d days (max %d)", span, historyMaxDays)
+		return time.Time{}, time.Time{}, fmt.Errorf("date range spans %d days, maximum is %d", span, historyMaxDays)
+	}
+	return startDate, endDate, nil
+}
+
+// fetchHistorical returns daily observations from weatherBackend through
+// the response cache. Past observations don't change, so they share the
+// forecast TTL.
+func fetchHistorical(ctx context.Context, lat, lon float64, start, end string) (OpenMeteoForecastResponse, string, error) {
+	fetch := func() (OpenMeteoForecastResponse, error) {
+		return weatherBackend.Historical(ctx, lat, lon, start, end)
+	}
+	if responseCache == nil {
+		return cachedFetch(nil, "", 0, fetch)
+	}
+	key := responseCache.key("history", lat, lon, 0) + ":" + start + ":" + end
+	return cachedFetch(responseCache, key, responseCache.forecastTTL, fetch)
+}
+
+// Tool handler
+
+func getHistoricalWeather(ctx context.Context, _ *mcp.CallToolRequest, input GetHistoricalWeatherInput) (*mcp.CallToolResult, HistoricalWeatherOutput, error) {
+	log.Printf("[DEBUG] get_historical_weather tool called with input: latitude=%.4f, longitude=%.4f, start_date=%s, end_date=%s, units=%s",
+		input.Latitude, input.Longitude, input.StartDate, input.EndDate, input.Units)
+
+	if err := coords.Validate(input.Latitude, input.Longitude); err != nil {
+		return nil, HistoricalWeatherOutput{}, err
+	}
+	units, err := parseUnits(input.Units)
+	if err != nil {
+		return nil, HistoricalWeatherOutput{}, err
+	}
+	startDate, endDate, err := parseDateRange(input.StartDate, input.EndDate)
+	if err != nil {
+		return nil, HistoricalWeatherOutput{}, err
+	}
+	start, end := startDate.Format("2006-01-02"), endDate.Format("2006-01-02")
+
+	apiResp, cacheStatus, err := fetchHistorical(ctx, input.Latitude, input.Longitude, start, end)
+	if err != nil {
+		log.Printf("[ERROR] Failed to fetch historical weather data: %v", err)
+		return nil, HistoricalWeatherOutput{}, fmt.Errorf("failed to fetch historical weather data: %w", err)
+	}
+
+	daily := buildDailyForecasts(apiResp, units)
+	log.Printf("[DEBUG] Historical weather retrieved: %d days of data, cache=%s", len(daily), cacheStatus)
+
+	return cacheResult(cacheStatus), HistoricalWeatherOutput{
+		Latitude:  apiResp.Latitude,
+		Longitude: apiResp.Longitude,
+		StartDate: start,
+		EndDate:   end,
+		Daily:     daily,
+		Units:     units.Units,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"shared/coords"
+)
+
+// Tool input/output types
+
+type GetHourlyForecastInput struct {
+	Latitude  float64 `json:"latitude" jsonschema:"latitude coordinate (-90 to 90)"`
+	Longitude float64 `json:"longitude" jsonschema:"longitude coordinate (-180 to 180)"`
+	Hours     int     `json:"hours,omitempty" jsonschema:"number of forecast hours (1-48, default 24)"`
+	Units     string  `json:"units,omitempty" jsonschema:"unit system for the output: metric (default), imperial, or si"`
+}
+
+type HourlyForecast struct {
+	Time                     string  `json:"time"`
+	Temperature              float64 `json:"temperature"`
+	ApparentTemperature      float64 `json:"apparent_temperature"`
+	PrecipitationProbability int     `json:"precipitation_probability_percent"`
+	Precipitation            float64 `json:"precipitation"`
+	WindSpeed                float64 `json:"wind_speed"`
+	WindGusts                float64 `json:"wind_gusts"`
+	WindDirection            int     `json:"wind_direction_degrees"`
+	Humidity                 int     `json:"relative_humidity_percent"`
+	WeatherCode              int     `json:"weather_code"`
+	Description              string  `json:"description"`
+}
+
+type HourlyForecastOutput struct {
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	Hourly    []HourlyForecast `json:"hourly"`
+	Units     Units            `json:"units"`
+}
+
+// Open-Meteo API response structure
+type OpenMeteoHourlyResponse struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Hourly    struct {
+		Time                     []string  `json:"time"`
+		Temperature2m            []float64 `json:"temperature_2m"`
+		ApparentTemperature      []float64 `json:"apparent_temperature"`
+		PrecipitationProbability []int     `json:"precipitation_probability"`
+		Precipitation            []float64 `json:"precipitation"`
+		WindSpeed10m             []float64 `json:"wind_speed_10m"`
+		WindGusts10m             []float64 `json:"wind_gusts_10m"`
+		WindDirection10m         []int     `json:"wind_direction_10m"`
+		RelativeHumidity2m       []int     `json:"relative_humidity_2m"`
+		WeatherCode              []int     `json:"weather_code"`
+	} `json:"hourly"`
+}
+
+const maxForecastHours = 48
+
+// Tool handler
+
+func getHourlyForecast(ctx context.Context, _ *mcp.CallToolRequest, input GetHourlyForecastInput) (*mcp.CallToolResult, HourlyForecastOutput, error) {
+	log.Printf("[DEBUG] get_hourly_forecast tool called with input: latitude=%.4f, longitude=%.4f, hours=%d, units=%s",
+		input.Latitude, input.Longitude, input.Hours, input.Units)
+
+	if err := coords.Validate(input.Latitude, input.Longitude); err != nil {
+		return nil, HourlyForecastOutput{}, err
+	}
+	units, err := parseUnits(input.Units)
+	if err != nil {
+		return nil, HourlyForecastOutput{}, err
+	}
+
+	// Validate and set default hours
+	hours := input.Hours
+	if hours <= 0 {
+		hours = 24
+		log.Printf("[DEBUG] Hours was <= 0, using default: %d", hours)
+	}
+	if hours > maxForecastHours {
+		hours = maxForecastHours
+		log.Printf("[DEBUG] Hours exceeded max, capping at: %d", hours)
+	}
+
+	apiResp, cacheStatus, err := fetchHourly(ctx, input.Latitude, input.Longitude, hours)
+	if err != nil {
+		log.Printf("[ERROR] Failed to fetch hourly forecast data: %v", err)
+		return nil, HourlyForecastOutput{}, fmt.Errorf("failed to fetch hourly forecast data: %w", err)
+	}
+
+	// Build hourly forecasts. Open-Meteo returns parallel arrays; stop at the
+	// shortest one so a truncated response can't cause an index panic.
+	h := apiResp.Hourly
+	n := min(len(h.Time), len(h.Temperature2m), len(h.ApparentTemperature),
+		len(h.PrecipitationProbability), len(h.Precipitation), len(h.WindSpeed10m),
+		len(h.WindGusts10m), len(h.WindDirection10m), len(h.RelativeHumidity2m),
+		len(h.WeatherCode), hours)
+
+	hourly := make([]HourlyForecast, 0, n)
+	for i := 0; i < n; i++ {
+		hourly = append(hourly, HourlyForecast{
+			Time:                     h.Time[i],
+			Temperature:              units.temperature(h.Temperature2m[i]),
+			ApparentTemperature:      units.temperature(h.ApparentTemperature[i]),
+			PrecipitationProbability: h.PrecipitationProbability[i],
+			Precipitation:            units.precipitation(h.Precipitation[i]),
+			WindSpeed:                units.windSpeed(h.WindSpeed10m[i]),
+			WindGusts:                units.windSpeed(h.WindGusts10m[i]),
+			WindDirection:            h.WindDirection10m[i],
+			Humidity:                 h.RelativeHumidity2m[i],
+			WeatherCode:              h.WeatherCode[i],
+			Description:              getWeatherDescription(h.WeatherCode[i]),
+		})
+	}
+
+	log.Printf("[DEBUG] Hourly forecast retrieved: %d hours of data, cache=%s", len(hourly), cacheStatus)
+	result := HourlyForecastOutput{
+		Latitude:  apiResp.Latitude,
+		Longitude: apiResp.Longitude,
+		Hourly:    hourly,
+		Units:     units.Units,
+	}
+	return cacheResult(cacheStatus), result, nil
+}
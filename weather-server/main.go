@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"time"
 
@@ -20,41 +19,49 @@ import (
 // Tool input/output types
 
 type GetCurrentWeatherInput struct {
-	Latitude  float64 `json:"latitude" jsonschema:"latitude coordinate (-90 to 90)"`
-	Longitude float64 `json:"longitude" jsonschema:"longitude coordinate (-180 to 180)"`
+	Latitude  *float64 `json:"latitude,omitempty" jsonschema:"latitude coordinate (-90 to 90); omit to use location"`
+	Longitude *float64 `json:"longitude,omitempty" jsonschema:"longitude coordinate (-180 to 180); omit to use location"`
+	Location  string   `json:"location,omitempty" jsonschema:"place name used when coordinates are omitted, optionally qualified, e.g. Springfield, Illinois"`
+	Units     string   `json:"units,omitempty" jsonschema:"unit system for the output: metric (default), imperial, or si"`
 }
 
 type CurrentWeatherOutput struct {
-	Latitude      float64 `json:"latitude"`
-	Longitude     float64 `json:"longitude"`
-	Temperature   float64 `json:"temperature_celsius"`
-	WindSpeed     float64 `json:"wind_speed_kmh"`
-	WindDirection int     `json:"wind_direction_degrees"`
-	WeatherCode   int     `json:"weather_code"`
-	Description   string  `json:"description"`
-	IsDay         bool    `json:"is_day"`
-	Time          string  `json:"time"`
+	Latitude      float64      `json:"latitude"`
+	Longitude     float64      `json:"longitude"`
+	Temperature   float64      `json:"temperature"`
+	WindSpeed     float64      `json:"wind_speed"`
+	WindDirection int          `json:"wind_direction_degrees"`
+	WeatherCode   int          `json:"weather_code"`
+	Description   string       `json:"description"`
+	IsDay         bool         `json:"is_day"`
+	Time          string       `json:"time"`
+	Units         Units        `json:"units"`
+	Location      *GeoLocation `json:"location,omitempty"`
 }
 
 type GetForecastInput struct {
-	Latitude  float64 `json:"latitude" jsonschema:"latitude coordinate (-90 to 90)"`
-	Longitude float64 `json:"longitude" jsonschema:"longitude coordinate (-180 to 180)"`
-	Days      int     `json:"days,omitempty" jsonschema:"number of forecast days (1-7, default 3)"`
+	Latitude  *float64 `json:"latitude,omitempty" jsonschema:"latitude coordinate (-90 to 90); omit to use location"`
+	Longitude *float64 `json:"longitude,omitempty" jsonschema:"longitude coordinate (-180 to 180); omit to use location"`
+	Location  string   `json:"location,omitempty" jsonschema:"place name used when coordinates are omitted, optionally qualified, e.g. Springfield, Illinois"`
+	Days      int      `json:"days,omitempty" jsonschema:"number of forecast days (1-7, default 3)"`
+	Units     string   `json:"units,omitempty" jsonschema:"unit system for the output: metric (default), imperial, or si"`
 }
 
 type DailyForecast struct {
 	Date             string  `json:"date"`
-	TempMax          float64 `json:"temp_max_celsius"`
-	TempMin          float64 `json:"temp_min_celsius"`
+	TempMax          float64 `json:"temp_max"`
+	TempMin          float64 `json:"temp_min"`
 	WeatherCode      int     `json:"weather_code"`
 	Description      string  `json:"description"`
-	PrecipitationSum float64 `json:"precipitation_mm"`
+	PrecipitationSum float64 `json:"precipitation"`
 }
 
 type ForecastOutput struct {
 	Latitude  float64         `json:"latitude"`
 	Longitude float64         `json:"longitude"`
 	Daily     []DailyForecast `json:"daily"`
+	Units     Units           `json:"units"`
+	Location  *GeoLocation    `json:"location,omitempty"`
 }
 
 // Weather code to description mapping
@@ -115,80 +122,74 @@ type OpenMeteoForecastResponse struct {
 		Temperature2mMin []float64 `json:"temperature_2m_min"`
 		WeatherCode      []int     `json:"weathercode"`
 		PrecipitationSum []float64 `json:"precipitation_sum"`
+		WindGusts10mMax  []float64 `json:"wind_gusts_10m_max"`
 	} `json:"daily"`
 }
 
 // Tool handlers
 
-func getCurrentWeather(_ context.Context, _ *mcp.CallToolRequest, input GetCurrentWeatherInput) (*mcp.CallToolResult, CurrentWeatherOutput, error) {
-	log.Printf("[DEBUG] get_current_weather tool called with input: latitude=%.4f, longitude=%.4f", input.Latitude, input.Longitude)
+func getCurrentWeather(ctx context.Context, _ *mcp.CallToolRequest, input GetCurrentWeatherInput) (*mcp.CallToolResult, CurrentWeatherOutput, error) {
+	log.Printf("[DEBUG] get_current_weather tool called with input: latitude=%s, longitude=%s, location=%s, units=%s",
+		formatCoordinate(input.Latitude), formatCoordinate(input.Longitude), input.Location, input.Units)
 
-	// Validate coordinates
-	if input.Latitude < -90 || input.Latitude > 90 {
-		log.Printf("[ERROR] Invalid latitude: %.4f (must be between -90 and 90)", input.Latitude)
-		return nil, CurrentWeatherOutput{}, fmt.Errorf("latitude must be between -90 and 90")
-	}
-	if input.Longitude < -180 || input.Longitude > 180 {
-		log.Printf("[ERROR] Invalid longitude: %.4f (must be between -180 and 180)", input.Longitude)
-		return nil, CurrentWeatherOutput{}, fmt.Errorf("longitude must be between -180 and 180")
+	units, err := parseUnits(input.Units)
+	if err != nil {
+		return nil, CurrentWeatherOutput{}, err
 	}
 
-	// Build API URL
-	apiURL := fmt.Sprintf(
-		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&current_weather=true",
-		input.Latitude, input.Longitude,
-	)
-	log.Printf("[DEBUG] Fetching weather from API: %s", apiURL)
-
-	// Fetch from API
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(apiURL)
+	lat, lon, place, err := resolveLocation(ctx, input.Location, input.Latitude, input.Longitude)
 	if err != nil {
-		log.Printf("[ERROR] Failed to fetch weather data: %v", err)
-		return nil, CurrentWeatherOutput{}, fmt.Errorf("failed to fetch weather data: %w", err)
+		return nil, CurrentWeatherOutput{}, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[ERROR] API returned non-OK status: %d", resp.StatusCode)
-		return nil, CurrentWeatherOutput{}, fmt.Errorf("API returned status %d", resp.StatusCode)
+	result, cacheStatus, err := currentWeather(ctx, lat, lon, units)
+	if err != nil {
+		return nil, CurrentWeatherOutput{}, err
 	}
+	result.Location = place
 
-	var apiResp OpenMeteoCurrentResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		log.Printf("[ERROR] Failed to parse API response: %v", err)
-		return nil, CurrentWeatherOutput{}, fmt.Errorf("failed to parse API response: %w", err)
+	return cacheResult(cacheStatus), result, nil
+}
+
+// currentWeather fetches current conditions for validated coordinates and
+// converts them to the requested units.
+func currentWeather(ctx context.Context, lat, lon float64, units unitSystem) (CurrentWeatherOutput, string, error) {
+	apiResp, cacheStatus, err := fetchCurrent(ctx, lat, lon)
+	if err != nil {
+		log.Printf("[ERROR] Failed to fetch weather data: %v", err)
+		return CurrentWeatherOutput{}, cacheStatus, fmt.Errorf("failed to fetch weather data: %w", err)
 	}
 
 	result := CurrentWeatherOutput{
 		Latitude:      apiResp.Latitude,
 		Longitude:     apiResp.Longitude,
-		Temperature:   apiResp.CurrentWeather.Temperature,
-		WindSpeed:     apiResp.CurrentWeather.WindSpeed,
+		Temperature:   units.temperature(apiResp.CurrentWeather.Temperature),
+		WindSpeed:     units.windSpeed(apiResp.CurrentWeather.WindSpeed),
 		WindDirection: apiResp.CurrentWeather.WindDirection,
 		WeatherCode:   apiResp.CurrentWeather.WeatherCode,
 		Description:   getWeatherDescription(apiResp.CurrentWeather.WeatherCode),
 		IsDay:         apiResp.CurrentWeather.IsDay == 1,
 		Time:          apiResp.CurrentWeather.Time,
+		Units:         units.Units,
 	}
-	log.Printf("[DEBUG] Weather data retrieved: temp=%.1f°C, description=%s, wind=%.1f km/h",
-		result.Temperature, result.Description, result.WindSpeed)
+	log.Printf("[DEBUG] Weather data retrieved: temp=%.1f%s, description=%s, wind=%.1f %s, cache=%s",
+		result.Temperature, units.Temperature, result.Description, result.WindSpeed, units.WindSpeed, cacheStatus)
 
-	return nil, result, nil
+	return result, cacheStatus, nil
 }
 
-func getForecast(_ context.Context, _ *mcp.CallToolRequest, input GetForecastInput) (*mcp.CallToolResult, ForecastOutput, error) {
-	log.Printf("[DEBUG] get_forecast tool called with input: latitude=%.4f, longitude=%.4f, days=%d",
-		input.Latitude, input.Longitude, input.Days)
+func getForecast(ctx context.Context, _ *mcp.CallToolRequest, input GetForecastInput) (*mcp.CallToolResult, ForecastOutput, error) {
+	log.Printf("[DEBUG] get_forecast tool called with input: latitude=%s, longitude=%s, location=%s, days=%d, units=%s",
+		formatCoordinate(input.Latitude), formatCoordinate(input.Longitude), input.Location, input.Days, input.Units)
 
-	// Validate coordinates
-	if input.Latitude < -90 || input.Latitude > 90 {
-		log.Printf("[ERROR] Invalid latitude: %.4f (must be between -90 and 90)", input.Latitude)
-		return nil, ForecastOutput{}, fmt.Errorf("latitude must be between -90 and 90")
+	units, err := parseUnits(input.Units)
+	if err != nil {
+		return nil, ForecastOutput{}, err
 	}
-	if input.Longitude < -180 || input.Longitude > 180 {
-		log.Printf("[ERROR] Invalid longitude: %.4f (must be between -180 and 180)", input.Longitude)
-		return nil, ForecastOutput{}, fmt.Errorf("longitude must be between -180 and 180")
+
+	lat, lon, place, err := resolveLocation(ctx, input.Location, input.Latitude, input.Longitude)
+	if err != nil {
+		return nil, ForecastOutput{}, err
 	}
 
 	// Validate and set default days
@@ -202,60 +203,57 @@ func getForecast(_ context.Context, _ *mcp.CallToolRequest, input GetForecastInp
 		log.Printf("[DEBUG] Days exceeded max, capping at: %d", days)
 	}
 
-	// Build API URL
-	params := url.Values{}
-	params.Set("latitude", fmt.Sprintf("%f", input.Latitude))
-	params.Set("longitude", fmt.Sprintf("%f", input.Longitude))
-	params.Set("daily", "temperature_2m_max,temperature_2m_min,weathercode,precipitation_sum")
-	params.Set("forecast_days", fmt.Sprintf("%d", days))
-	params.Set("timezone", "auto")
-
-	apiURL := "https://api.open-meteo.com/v1/forecast?" + params.Encode()
-	log.Printf("[DEBUG] Fetching forecast from API: %s", apiURL)
-
-	// Fetch from API
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(apiURL)
+	apiResp, cacheStatus, err := fetchForecast(ctx, lat, lon, days)
 	if err != nil {
 		log.Printf("[ERROR] Failed to fetch forecast data: %v", err)
 		return nil, ForecastOutput{}, fmt.Errorf("failed to fetch forecast data: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("[ERROR] API returned non-OK status: %d", resp.StatusCode)
-		return nil, ForecastOutput{}, fmt.Errorf("API returned status %d", resp.StatusCode)
+	daily := buildDailyForecasts(apiResp, units)
+
+	log.Printf("[DEBUG] Forecast retrieved: %d days of data, cache=%s", len(daily), cacheStatus)
+	result := ForecastOutput{
+		Latitude:  apiResp.Latitude,
+		Longitude: apiResp.Longitude,
+		Daily:     daily,
+		Units:     units.Units,
+		Location:  place,
 	}
+	return cacheResult(cacheStatus), result, nil
+}
 
-	var apiResp OpenMeteoForecastResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		log.Printf("[ERROR] Failed to parse API response: %v", err)
-		return nil, ForecastOutput{}, fmt.Errorf("failed to parse API response: %w", err)
+// flagOrEnv returns the flag value if set, then the environment variable,
+// then the default.
+func flagOrEnv(flagValue, envName, def string) string {
+	if flagValue != "" {
+		return flagValue
 	}
+	if v := os.Getenv(envName); v != "" {
+		return v
+	}
+	return def
+}
 
-	// Build daily forecasts
-	var daily []DailyForecast
-	for i := range apiResp.Daily.Time {
-		if i >= len(apiResp.Daily.Temperature2mMax) {
-			break
-		}
+// buildDailyForecasts converts Open-Meteo's parallel daily arrays into
+// DailyForecast values in the requested units. It stops at the shortest
+// array so a truncated response can't cause an index panic.
+func buildDailyForecasts(apiResp OpenMeteoForecastResponse, units unitSystem) []DailyForecast {
+	d := apiResp.Daily
+	n := min(len(d.Time), len(d.Temperature2mMax), len(d.Temperature2mMin),
+		len(d.WeatherCode), len(d.PrecipitationSum))
+
+	daily := make([]DailyForecast, 0, n)
+	for i := 0; i < n; i++ {
 		daily = append(daily, DailyForecast{
-			Date:             apiResp.Daily.Time[i],
-			TempMax:          apiResp.Daily.Temperature2mMax[i],
-			TempMin:          apiResp.Daily.Temperature2mMin[i],
-			WeatherCode:      apiResp.Daily.WeatherCode[i],
-			Description:      getWeatherDescription(apiResp.Daily.WeatherCode[i]),
-			PrecipitationSum: apiResp.Daily.PrecipitationSum[i],
+			Date:             d.Time[i],
+			TempMax:          units.temperature(d.Temperature2mMax[i]),
+			TempMin:          units.temperature(d.Temperature2mMin[i]),
+			WeatherCode:      d.WeatherCode[i],
+			Description:      getWeatherDescription(d.WeatherCode[i]),
+			PrecipitationSum: units.precipitation(d.PrecipitationSum[i]),
 		})
 	}
-
-	log.Printf("[DEBUG] Forecast retrieved: %d days of data", len(daily))
-	result := ForecastOutput{
-		Latitude:  apiResp.Latitude,
-		Longitude: apiResp.Longitude,
-		Daily:     daily,
-	}
-	return nil, result, nil
+	return daily
 }
 
 // corsMiddleware adds CORS headers and handles preflight requests
@@ -287,6 +285,22 @@ func main() {
 	// Define command-line flags
 	portFlag := flag.String("port", "", "HTTP port to listen on (overrides WEATHER_SERVER_PORT env var)")
 	corsFlag := flag.Bool("cors", true, "Enable CORS middleware (needed for browser-based clients like mcp-inspector)")
+	backendFlag := flag.String("backend", "", "Weather data backend: openmeteo or fixture (overrides WEATHER_BACKEND env var)")
+	openMeteoURLFlag := flag.String("openmeteo-url", "", "Open-Meteo API base URL (overrides WEATHER_OPENMETEO_URL env var)")
+	geocodingURLFlag := flag.String("geocoding-url", "", "Open-Meteo geocoding API base URL (overrides WEATHER_GEOCODING_URL env var)")
+	archiveURLFlag := flag.String("archive-url", "", "Open-Meteo historical archive API base URL (overrides WEATHER_ARCHIVE_URL env var)")
+	airQualityURLFlag := flag.String("air-quality-url", "", "Open-Meteo air quality API base URL (overrides WEATHER_AIR_QUALITY_URL env var)")
+	alertRulesFlag := flag.String("alert-rules", "", "YAML or JSON file with weather alert rules (overrides WEATHER_ALERT_RULES env var)")
+	fixtureDirFlag := flag.String("fixture-dir", "", "Directory with fixture JSON files for the fixture backend (overrides WEATHER_FIXTURE_DIR env var)")
+	cacheFlag := flag.Bool("cache", true, "Cache upstream weather responses in memory")
+	cacheSizeFlag := flag.Int("cache-size", 1000, "Maximum number of cached responses (least recently used are evicted)")
+	cachePrecisionFlag := flag.Int("cache-precision", 2, "Decimal places coordinates are rounded to when building cache keys")
+	cacheCurrentTTLFlag := flag.Duration("cache-current-ttl", 5*time.Minute, "How long current conditions stay cached")
+	cacheForecastTTLFlag := flag.Duration("cache-forecast-ttl", 30*time.Minute, "How long forecasts stay cached")
+	historyMaxDaysFlag := flag.Int("history-max-days", 31, "Longest date range accepted by get_historical_weather, in days")
+	batchMaxLocationsFlag := flag.Int("batch-max-locations", 10, "Maximum number of locations per get_weather_batch call")
+	batchWorkersFlag := flag.Int("batch-workers", 4, "Number of concurrent upstream lookups per get_weather_batch call")
+	upstreamAttemptsFlag := flag.Int("upstream-attempts", 3, "Maximum attempts per upstream API request, including the first")
 	flag.Parse()
 
 	// Get port from command-line flag, environment, or use default
@@ -298,6 +312,69 @@ func main() {
 		}
 	}
 
+	upstream.MaxAttempts = *upstreamAttemptsFlag
+
+	if *historyMaxDaysFlag <= 0 {
+		log.Fatalf("[ERROR] -history-max-days must be positive")
+	}
+	historyMaxDays = *historyMaxDaysFlag
+
+	if *batchMaxLocationsFlag <= 0 || *batchWorkersFlag <= 0 {
+		log.Fatalf("[ERROR] -batch-max-locations and -batch-workers must be positive")
+	}
+	batchMaxLocations = *batchMaxLocationsFlag
+	batchWorkers = *batchWorkersFlag
+
+	// Select the upstream weather data backend
+	backend := flagOrEnv(*backendFlag, "WEATHER_BACKEND", "openmeteo")
+	switch backend {
+	case "openmeteo":
+		openMeteoURL := flagOrEnv(*openMeteoURLFlag, "WEATHER_OPENMETEO_URL", defaultOpenMeteoURL)
+		geocodingURL := flagOrEnv(*geocodingURLFlag, "WEATHER_GEOCODING_URL", defaultGeocodingURL)
+		archiveURL := flagOrEnv(*archiveURLFlag, "WEATHER_ARCHIVE_URL", defaultArchiveURL)
+		airQualityURL := flagOrEnv(*airQualityURLFlag, "WEATHER_AIR_QUALITY_URL", defaultAirQualityURL)
+		weatherBackend = newOpenMeteoBackend(openMeteoURL, geocodingURL, archiveURL, airQualityURL)
+		log.Printf("[DEBUG] Using Open-Meteo backend: forecast=%s, geocoding=%s, archive=%s, air_quality=%s",
+			openMeteoURL, geocodingURL, archiveURL, airQualityURL)
+	case "fixture":
+		fixtureDir := flagOrEnv(*fixtureDirFlag, "WEATHER_FIXTURE_DIR", "fixtures")
+		fb, err := newFixtureBackend(fixtureDir)
+		if err != nil {
+			log.Fatalf("[ERROR] Failed to set up fixture backend: %v", err)
+		}
+		weatherBackend = fb
+		log.Printf("[DEBUG] Using fixture backend: %s", fixtureDir)
+	default:
+		log.Fatalf("[ERROR] Unknown backend %q (must be openmeteo or fixture)", backend)
+	}
+
+	// Load weather alert rules
+	if path := flagOrEnv(*alertRulesFlag, "WEATHER_ALERT_RULES", ""); path != "" {
+		rules, err := loadAlertRules(path)
+		if err != nil {
+			log.Fatalf("[ERROR] Failed to load alert rules: %v", err)
+		}
+		alertRules = rules
+		log.Printf("[DEBUG] Loaded %d alert rules from %s", len(rules), path)
+	} else {
+		log.Printf("[DEBUG] Using %d default alert rules", len(alertRules))
+	}
+
+	// Set up the response cache
+	if *cacheFlag {
+		if *cacheSizeFlag <= 0 {
+			log.Fatalf("[ERROR] -cache-size must be positive")
+		}
+		if *cachePrecisionFlag < 0 || *cachePrecisionFlag > 6 {
+			log.Fatalf("[ERROR] -cache-precision must be between 0 and 6")
+		}
+		responseCache = newWeatherCache(*cacheSizeFlag, *cachePrecisionFlag, *cacheCurrentTTLFlag, *cacheForecastTTLFlag)
+		log.Printf("[DEBUG] Response cache enabled: size=%d, precision=%d, current_ttl=%s, forecast_ttl=%s",
+			*cacheSizeFlag, *cachePrecisionFlag, *cacheCurrentTTLFlag, *cacheForecastTTLFlag)
+	} else {
+		log.Printf("[DEBUG] Response cache disabled")
+	}
+
 	// Create MCP server
 	log.Printf("[DEBUG] Creating MCP server...")
 	server := mcp.NewServer(
@@ -313,7 +390,7 @@ func main() {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "get_current_weather",
-			Description: "Get current weather conditions for a location specified by latitude and longitude coordinates.",
+			Description: "Get current weather conditions for a location specified by latitude and longitude coordinates, or by place name, in metric, imperial, or SI units.",
 		},
 		getCurrentWeather,
 	)
@@ -321,11 +398,67 @@ func main() {
 	mcp.AddTool(server,
 		&mcp.Tool{
 			Name:        "get_forecast",
-			Description: "Get weather forecast for a location. Returns daily forecasts including temperature range, weather conditions, and precipitation.",
+			Description: "Get weather forecast for a location specified by coordinates or place name. Returns daily forecasts including temperature range, weather conditions, and precipitation in metric, imperial, or SI units.",
 		},
 		getForecast,
 	)
-	log.Printf("[DEBUG] Tools added: get_current_weather, get_forecast")
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_hourly_forecast",
+			Description: "Get an hourly weather forecast for a location for up to 48 hours. Returns temperature, apparent temperature, precipitation probability and amount, wind speed, gusts and direction, humidity, and weather conditions for each hour.",
+		},
+		getHourlyForecast,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "geocode_location",
+			Description: "Look up a place name and return matching locations with coordinates, country, region, timezone, and population.",
+		},
+		geocodeLocation,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_historical_weather",
+			Description: "Get observed daily weather for a location over a past date range. Returns the same daily fields as get_forecast: temperature range, weather conditions, and precipitation.",
+		},
+		getHistoricalWeather,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_air_quality",
+			Description: "Get current air quality for a location specified by coordinates or place name. Returns PM2.5, PM10, ozone, and nitrogen dioxide concentrations plus European and US AQI values with category labels.",
+		},
+		getAirQuality,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_uv_index",
+			Description: "Get the current UV index for a location specified by coordinates or place name, with the clear-sky UV index and a WHO exposure category.",
+		},
+		getUVIndex,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_weather_alerts",
+			Description: "Check the daily forecast for a location against configured alert rules (heavy rain, extreme heat, thunderstorms, strong wind gusts, and so on). Returns alerts with severity, date range, and the triggering values in metric units.",
+		},
+		getWeatherAlerts,
+	)
+
+	mcp.AddTool(server,
+		&mcp.Tool{
+			Name:        "get_weather_batch",
+			Description: "Get current weather for several locations in one call. Locations are fetched concurrently; each result carries either the weather or an error, so one bad location does not fail the whole batch.",
+		},
+		getWeatherBatch,
+	)
+	log.Printf("[DEBUG] Tools added: get_current_weather, get_forecast, get_hourly_forecast, geocode_location, get_historical_weather, get_air_quality, get_uv_index, get_weather_alerts, get_weather_batch")
 
 	// Create StreamableHTTP handler
 	log.Printf("[DEBUG] Creating StreamableHTTP handler...")
@@ -388,7 +521,8 @@ func main() {
 	log.Printf("Address: %s", addr)
 	log.Printf("Health endpoint: http://localhost%s/health", addr)
 	log.Printf("MCP endpoint: http://localhost%s/mcp", addr)
-	log.Printf("Available tools: get_current_weather, get_forecast")
+	log.Printf("Backend: %s", backend)
+	log.Printf("Available tools: get_current_weather, get_forecast, get_hourly_forecast, geocode_location, get_historical_weather, get_air_quality, get_uv_index, get_weather_alerts, get_weather_batch")
 	log.Printf("========================================")
 	log.Printf("[DEBUG] Starting HTTP server on %s...", addr)
 
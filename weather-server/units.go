@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Units names the unit of each measurement in a weather tool output.
+type Units struct {
+	System        string `json:"system"`
+	Temperature   string `json:"temperature"`
+	WindSpeed     string `json:"wind_speed"`
+	Precipitation string `json:"precipitation"`
+}
+
+// unitSystem converts Open-Meteo's metric values (°C, km/h, mm) into the
+// units requested by the caller.
+type unitSystem struct {
+	Units
+	temperature   func(celsius float64) float64
+	windSpeed     func(kmh float64) float64
+	precipitation func(mm float64) float64
+}
+
+func identity(v float64) float64 { return v }
+
+var unitSystems = map[string]unitSystem{
+	"metric": {
+		Units:         Units{System: "metric", Temperature: "°C", WindSpeed: "km/h", Precipitation: "mm"},
+		temperature:   identity,
+		windSpeed:     identity,
+		precipitation: identity,
+	},
+	"imperial": {
+		Units:         Units{System: "imperial", Temperature: "°F", WindSpeed: "mph", Precipitation: "in"},
+		temperature:   func(c float64) float64 { return round2(c*9/5 + 32) },
+		windSpeed:     func(kmh float64) float64 { return round2(kmh / 1.609344) },
+		precipitation: func(mm float64) float64 { return round2(mm / 25.4) },
+	},
+	"si": {
+		Units:         Units{System: "si", Temperature: "K", WindSpeed: "m/s", Precipitation: "mm"},
+		temperature:   func(c float64) float64 { return round2(c + 273.15) },
+		windSpeed:     func(kmh float64) float64 { return round2(kmh / 3.6) },
+		precipitation: identity,
+	},
+}
+
+// parseUnits returns the unit system named by s, defaulting to metric.
+func parseUnits(s string) (unitSystem, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "" {
+		name = "metric"
+	}
+	u, ok := unitSystems[name]
+	if !ok {
+		log.Printf("[ERROR] Invalid units: %s (must be metric, imperial, or si)", s)
+		return unitSystem{}, fmt.Errorf("units must be metric, imperial, or si")
+	}
+	return u, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
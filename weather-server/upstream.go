@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// WeatherBackend is the upstream source of Open-Meteo payloads. The tool
+// handlers only talk to this interface, so they can run against the live API
+// or against a directory of canned responses.
+type WeatherBackend interface {
+	Current(ctx context.Context, lat, lon float64) (OpenMeteoCurrentResponse, error)
+	Forecast(ctx context.Context, lat, lon float64, days int) (OpenMeteoForecastResponse, error)
+	Hourly(ctx context.Context, lat, lon float64, hours int) (OpenMeteoHourlyResponse, error)
+	Geocode(ctx context.Context, name, countryCode string, count int) (OpenMeteoGeocodingResponse, error)
+	Historical(ctx context.Context, lat, lon float64, start, end string) (OpenMeteoForecastResponse, error)
+	AirQuality(ctx context.Context, lat, lon float64) (OpenMeteoAirQualityResponse, error)
+}
+
+// weatherBackend is configured in main from the -backend flag.
+var weatherBackend WeatherBackend = newOpenMeteoBackend(defaultOpenMeteoURL, defaultGeocodingURL, defaultArchiveURL, defaultAirQualityURL)
+
+const (
+	defaultOpenMeteoURL  = "https://api.open-meteo.com/v1"
+	defaultGeocodingURL  = "https://geocoding-api.open-meteo.com/v1"
+	defaultArchiveURL    = "https://archive-api.open-meteo.com/v1"
+	defaultAirQualityURL = "https://air-quality-api.open-meteo.com/v1"
+)
+
+// openMeteoBackend fetches data from the Open-Meteo HTTP APIs using the
+// shared upstream client.
+type openMeteoBackend struct {
+	baseURL       string
+	geocodingURL  string
+	archiveURL    string
+	airQualityURL string
+}
+
+func newOpenMeteoBackend(baseURL, geocodingURL, archiveURL, airQualityURL string) *openMeteoBackend {
+	return &openMeteoBackend{
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		geocodingURL:  strings.TrimRight(geocodingURL, "/"),
+		archiveURL:    strings.TrimRight(archiveURL, "/"),
+		airQualityURL: strings.TrimRight(airQualityURL, "/"),
+	}
+}
+
+func (b *openMeteoBackend) Current(ctx context.Context, lat, lon float64) (OpenMeteoCurrentResponse, error) {
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", lat))
+	params.Set("longitude", fmt.Sprintf("%f", lon))
+	params.Set("current_weather", "true")
+
+	var apiResp OpenMeteoCurrentResponse
+	err := b.getJSON(ctx, b.baseURL+"/forecast?"+params.Encode(), &apiResp)
+	return apiResp, err
+}
+
+func (b *openMeteoBackend) Forecast(ctx context.Context, lat, lon float64, days int) (OpenMeteoForecastResponse, error) {
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", lat))
+	params.Set("longitude", fmt.Sprintf("%f", lon))
+	params.Set("daily", "temperature_2m_max,temperature_2m_min,weathercode,precipitation_sum,wind_gusts_10m_max")
+	params.Set("forecast_days", fmt.Sprintf("%d", days))
+	params.Set("timezone", "auto")
+
+	var apiResp OpenMeteoForecastResponse
+	err := b.getJSON(ctx, b.baseURL+"/forecast?"+params.Encode(), &apiResp)
+	return apiResp, err
+}
+
+func (b *openMeteoBackend) Hourly(ctx context.Context, lat, lon float64, hours int) (OpenMeteoHourlyResponse, error) {
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", lat))
+	params.Set("longitude", fmt.Sprintf("%f", lon))
+	params.Set("hourly", "temperature_2m,apparent_temperature,precipitation_probability,precipitation,"+
+		"wind_speed_10m,wind_gusts_10m,wind_direction_10m,relative_humidity_2m,weather_code")
+	params.Set("forecast_hours", fmt.Sprintf("%d", hours))
+	params.Set("timezone", "auto")
+
+	var apiResp OpenMeteoHourlyResponse
+	err := b.getJSON(ctx, b.baseURL+"/forecast?"+params.Encode(), &apiResp)
+	return apiResp, err
+}
+
+func (b *openMeteoBackend) Geocode(ctx context.Context, name, countryCode string, count int) (OpenMeteoGeocodingResponse, error) {
+	params := url.Values{}
+	params.Set("name", name)
+	params.Set("count", fmt.Sprintf("%d", count))
+	params.Set("language", "en")
+	params.Set("format", "json")
+	if countryCode != "" {
+		params.Set("countryCode", strings.ToUpper(countryCode))
+	}
+
+	var apiResp OpenMeteoGeocodingResponse
+	err := b.getJSON(ctx, b.geocodingURL+"/search?"+params.Encode(), &apiResp)
+	return apiResp, err
+}
+
+func (b *openMeteoBackend) Historical(ctx context.Context, lat, lon float64, start, end string) (OpenMeteoForecastResponse, error) {
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", lat))
+	params.Set("longitude", fmt.Sprintf("%f", lon))
+	params.Set("start_date", start)
+	params.Set("end_date", end)
+	params.Set("daily", "temperature_2m_max,temperature_2m_min,weathercode,precipitation_sum")
+	params.Set("timezone", "auto")
+
+	var apiResp OpenMeteoForecastResponse
+	err := b.getJSON(ctx, b.archiveURL+"/archive?"+params.Encode(), &apiResp)
+	return apiResp, err
+}
+
+func (b *openMeteoBackend) AirQuality(ctx context.Context, lat, lon float64) (OpenMeteoAirQualityResponse, error) {
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", lat))
+	params.Set("longitude", fmt.Sprintf("%f", lon))
+	params.Set("current", "pm10,pm2_5,ozone,nitrogen_dioxide,european_aqi,us_aqi,uv_index,uv_index_clear_sky")
+	params.Set("timezone", "auto")
+
+	var apiResp OpenMeteoAirQualityResponse
+	err := b.getJSON(ctx, b.airQualityURL+"/air-quality?"+params.Encode(), &apiResp)
+	return apiResp, err
+}
+
+func (b *openMeteoBackend) getJSON(ctx context.Context, apiURL string, v any) error {
+	log.Printf("[DEBUG] Fetching from API: %s", apiURL)
+	return upstream.GetJSON(ctx, apiURL, v)
+}
+
+// fixtureBackend serves deterministic responses from JSON files on disk.
+// Files are named after the endpoint and the coordinates rounded to two
+// decimal places, e.g. current_52.52_13.41.json, forecast_52.52_13.41.json,
+// hourly_52.52_13.41.json, history_52.52_13.41.json or
+// airquality_52.52_13.41.json.
+// Each file holds the same JSON that Open-Meteo would have returned.
+type fixtureBackend struct {
+	dir string
+}
+
+func newFixtureBackend(dir string) (*fixtureBackend, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("fixture directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fixture directory: %s is not a directory", dir)
+	}
+	return &fixtureBackend{dir: dir}, nil
+}
+
+func (b *fixtureBackend) Current(_ context.Context, lat, lon float64) (OpenMeteoCurrentResponse, error) {
+	var apiResp OpenMeteoCurrentResponse
+	err := b.load("current", lat, lon, &apiResp)
+	return apiResp, err
+}
+
+func (b *fixtureBackend) Forecast(_ context.Context, lat, lon float64, days int) (OpenMeteoForecastResponse, error) {
+	var apiResp OpenMeteoForecastResponse
+	if err := b.load("forecast", lat, lon, &apiResp); err != nil {
+		return apiResp, err
+	}
+	if err := b.checkDaily("forecast", lat, lon, apiResp); err != nil {
+		return OpenMeteoForecastResponse{}, err
+	}
+
+	// Trim to the requested number of days, like forecast_days would
+	d := &apiResp.Daily
+	if days < len(d.Time) {
+		d.Time = d.Time[:days]
+		d.Temperature2mMax = truncate(d.Temperature2mMax, days)
+		d.Temperature2mMin = truncate(d.Temperature2mMin, days)
+		d.WeatherCode = truncate(d.WeatherCode, days)
+		d.PrecipitationSum = truncate(d.PrecipitationSum, days)
+		d.WindGusts10mMax = truncate(d.WindGusts10mMax, days)
+	}
+	return apiResp, nil
+}
+
+func (b *fixtureBackend) Hourly(_ context.Context, lat, lon float64, hours int) (OpenMeteoHourlyResponse, error) {
+	var apiResp OpenMeteoHourlyResponse
+	if err := b.load("hourly", lat, lon, &apiResp); err != nil {
+		return apiResp, err
+	}
+
+	// Trim to the requested number of hours, like forecast_hours would
+	h := &apiResp.Hourly
+	h.Time = truncate(h.Time, hours)
+	h.Temperature2m = truncate(h.Temperature2m, hours)
+	h.ApparentTemperature = truncate(h.ApparentTemperature, hours)
+	h.PrecipitationProbability = truncate(h.PrecipitationProbability, hours)
+	h.Precipitation = truncate(h.Precipitation, hours)
+	h.WindSpeed10m = truncate(h.WindSpeed10m, hours)
+	h.WindGusts10m = truncate(h.WindGusts10m, hours)
+	h.WindDirection10m = truncate(h.WindDirection10m, hours)
+	h.RelativeHumidity2m = truncate(h.RelativeHumidity2m, hours)
+	h.WeatherCode = truncate(h.WeatherCode, hours)
+	return apiResp, nil
+}
+
+// Geocode loads geocode_<name>.json, where name is lowercased with runs of
+// non-alphanumeric characters replaced by "-" (e.g. geocode_new-york.json).
+func (b *fixtureBackend) Geocode(_ context.Context, name, countryCode string, count int) (OpenMeteoGeocodingResponse, error) {
+	var apiResp OpenMeteoGeocodingResponse
+	path := filepath.Join(b.dir, "geocode_"+fixtureSlug(name)+".json")
+	if err := b.loadFile(path, &apiResp); err != nil {
+		if os.IsNotExist(err) {
+			// The real API answers unknown names with no results
+			return OpenMeteoGeocodingResponse{}, nil
+		}
+		return apiResp, err
+	}
+
+	// Apply the filters the real API would
+	if countryCode != "" {
+		results := apiResp.Results[:0]
+		for _, r := range apiResp.Results {
+			if strings.EqualFold(r.CountryCode, countryCode) {
+				results = append(results, r)
+			}
+		}
+		apiResp.Results = results
+	}
+	apiResp.Results = truncate(apiResp.Results, count)
+	return apiResp, nil
+}
+
+func (b *fixtureBackend) AirQuality(_ context.Context, lat, lon float64) (OpenMeteoAirQualityResponse, error) {
+	var apiResp OpenMeteoAirQualityResponse
+	err := b.load("airquality", lat, lon, &apiResp)
+	return apiResp, err
+}
+
+// checkDaily rejects a fixture whose daily arrays differ in length, which
+// the real API never returns.
+func (b *fixtureBackend) checkDaily(kind string, lat, lon float64, apiResp OpenMeteoForecastResponse) error {
+	d := apiResp.Daily
+	n := len(d.Time)
+	if len(d.Temperature2mMax) != n || len(d.Temperature2mMin) != n || len(d.WeatherCode) != n || len(d.PrecipitationSum) != n {
+		return fmt.Errorf("malformed fixture %s: daily arrays have different lengths", filepath.Base(b.fixturePath(kind, lat, lon)))
+	}
+	return nil
+}
+
+// Historical loads history_<lat>_<lon>.json and keeps only the days in
+// [start, end].
+func (b *fixtureBackend) Historical(_ context.Context, lat, lon float64, start, end string) (OpenMeteoForecastResponse, error) {
+	var apiResp OpenMeteoForecastResponse
+	if err := b.load("history", lat, lon, &apiResp); err != nil {
+		return apiResp, err
+	}
+
+	if err := b.checkDaily("history", lat, lon, apiResp); err != nil {
+		return OpenMeteoForecastResponse{}, err
+	}
+	d := apiResp.Daily
+	filtered := OpenMeteoForecastResponse{Latitude: apiResp.Latitude, Longitude: apiResp.Longitude}
+	f := &filtered.Daily
+	for i, day := range d.Time {
+		// YYYY-MM-DD strings sort chronologically
+		if day < start || day > end {
+			continue
+		}
+		f.Time = append(f.Time, day)
+		f.Temperature2mMax = append(f.Temperature2mMax, d.Temperature2mMax[i])
+		f.Temperature2mMin = append(f.Temperature2mMin, d.Temperature2mMin[i])
+		f.WeatherCode = append(f.WeatherCode, d.WeatherCode[i])
+		f.PrecipitationSum = append(f.PrecipitationSum, d.PrecipitationSum[i])
+	}
+	return filtered, nil
+}
+
+// fixtureSlug lowercases s and replaces runs of characters other than
+// letters and digits with a single "-".
+func fixtureSlug(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
+// fixturePath returns the file that holds the kind response for the
+// given coordinates.
+func (b *fixtureBackend) fixturePath(kind string, lat, lon float64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%s_%.2f_%.2f.json", kind, lat, lon))
+}
+
+func (b *fixtureBackend) load(kind string, lat, lon float64, v any) error {
+	path := b.fixturePath(kind, lat, lon)
+	err := b.loadFile(path, v)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no %s fixture for coordinates %.2f,%.2f (expected %s)", kind, lat, lon, filepath.Base(path))
+	}
+	return err
+}
+
+// loadFile decodes the JSON fixture at path into v. A missing file is
+// reported with an error satisfying os.IsNotExist.
+func (b *fixtureBackend) loadFile(path string, v any) error {
+	log.Printf("[DEBUG] Loading fixture: %s", path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Printf("[ERROR] Failed to parse fixture %s: %v", path, err)
+		return fmt.Errorf("failed to parse fixture %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func truncate[T any](s []T, n int) []T {
+	if n < len(s) {
+		return s[:n]
+	}
+	return s
+}